@@ -10,24 +10,49 @@ type LifeStage struct {
 	Post func(AgingTimeListener)
 }
 
+// Aging things close Stopped when destroyed, ending their AgeLoop
 type AgingTimeListener interface {
-	mud.TimeListener
+	mud.StoppableTimeListener
 	LifeStages() map[int]LifeStage
 	Stage() LifeStage
 	LastChange() int
 	SetStage(LifeStage)
 	SetStageChanged(int)
+	// Grown is the growth since the last change of stage. It is kept
+	// by the listener, so that it is saved with it.
+	Grown() float64
+	SetGrown(float64)
 }
 
 func addLs(ls LifeStage, lifeStages map[int]LifeStage) {
 	lifeStages[ls.StageNo] = ls
 }
 
+// growthFactor is how fast a grows where it is, which for things
+// outdoors depends on the weather
+func growthFactor(a AgingTimeListener) float64 {
+	if placed, ok := a.(interface{ Room() *mud.Room }); ok {
+		return mud.GrowthFactor(placed.Room())
+	}
+	return 1.0
+}
+
+/*
+ AgeLoop moves a through its life stages. Each heartbeat counts as
+ growthFactor ticks of growth, so stages pass faster in the rain. It
+ returns once a is stopped.
+ */
 func AgeLoop(a AgingTimeListener) {
 	for {
-		now := <- a.Ping()
+		var now int
+		select {
+		case now = <- a.Ping():
+		case <- a.Stopped():
+			return
+		}
 		stage := a.Stage()
-		if now > (a.LastChange() + stage.StageChangeDelay) {
+		a.SetGrown(a.Grown() + growthFactor(a))
+		if a.Grown() > float64(stage.StageChangeDelay) {
 			if(stage.Post != nil) { 
 				a.Stage().Post(a)
 			}
@@ -36,6 +61,7 @@ func AgeLoop(a AgingTimeListener) {
 				nextStage := (stage.StageNo + 1)
 				a.SetStage(a.LifeStages()[nextStage])
 				a.SetStageChanged(now)
+				a.SetGrown(0)
 			}
 
 			if(a.Stage().Pre != nil) {
@@ -0,0 +1,294 @@
+package main
+
+import ("fmt"
+	"os"
+	"strings"
+	"mud"
+	"mud/area"
+	"mud/simple")
+
+// LoadAreaFiles parses each of a comma-separated list of area files
+func LoadAreaFiles(files string) ([]*area.Area, error) {
+	areas := []*area.Area{}
+	for _, name := range(strings.Split(files, ",")) {
+		if name == "" {
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		a, err := area.Parse(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		for _, warning := range(a.Warnings) {
+			mud.Log("[WARN]", name, warning)
+		}
+		areas = append(areas, a)
+	}
+	return areas, nil
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// Prototype names are the first keyword and the vnum, e.g. "barrel 3001"
+func prototypeName(keywords []string, vnum int) string {
+	if len(keywords) == 0 {
+		return fmt.Sprintf("thing %d", vnum)
+	}
+	return fmt.Sprintf("%s %d", keywords[0], vnum)
+}
+
+func textHandles(keywords []string) []string {
+	handles := append([]string{}, keywords...)
+	if len(keywords) > 1 {
+		handles = append(handles, strings.Join(keywords, " "))
+	}
+	return handles
+}
+
+func NewAreaObject(universe *mud.Universe, o *area.Object) *simple.PhysicalObject {
+	obj := simple.NewPhysicalObject(universe)
+	obj.SetDescription(capitalize(o.Short))
+	obj.SetVisible(true)
+	obj.SetCarryable(o.WearFlags & area.WearTake != 0)
+	// Diku numbers item types; light is 1
+	obj.SetLightSource(o.ItemType == "light" || o.ItemType == "1")
+	obj.SetTextHandles(textHandles(o.Keywords)...)
+	if o.Weight > 0 {
+		obj.SetWeight(o.Weight)
+	}
+	obj.SetValue(mud.Currency(o.Cost))
+	if len(o.Extras) > 0 {
+		obj.SetLongDescription(o.Extras[0].Text)
+	} else {
+		obj.SetLongDescription(o.Long)
+	}
+	return obj
+}
+
+func NewAreaMobile(universe *mud.Universe, m *area.Mobile) *simple.NPC {
+	npc := simple.NewNPC(universe)
+	npc.SetName(m.Short)
+	npc.SetDescription(capitalize(m.Short))
+	npc.SetLongDescription(m.Description)
+	npc.SetVisible(true)
+	npc.SetCarryable(false)
+	npc.SetTextHandles(textHandles(m.Keywords)...)
+	return npc
+}
+
+/*
+ RegisterAreaPrototypes lets builders create the area's objects and
+ mobiles by name (see prototypeName).
+ */
+func RegisterAreaPrototypes(a *area.Area) {
+	for _, o := range(a.Objects) {
+		o := o
+		mud.Prototypes[prototypeName(o.Keywords, o.Vnum)] =
+			func(u *mud.Universe) mud.PhysicalObject { return NewAreaObject(u, o) }
+	}
+	for _, m := range(a.Mobiles) {
+		m := m
+		mud.Prototypes[prototypeName(m.Keywords, m.Vnum)] =
+			func(u *mud.Universe) mud.PhysicalObject { return NewAreaMobile(u, m) }
+	}
+}
+
+// areaImport holds what is shared between the areas being imported
+type areaImport struct {
+	universe *mud.Universe
+	rooms map[int]*mud.Room
+	objects map[int]*area.Object
+	mobiles map[int]*area.Mobile
+	doorStates map[[2]int]mud.DoorState
+}
+
+func (ai *areaImport) warn(a *area.Area, format string, args ...interface{}) {
+	mud.Log("[WARN]", a.Name + ":", fmt.Sprintf(format, args...))
+}
+
+func (ai *areaImport) buildRoom(z *mud.Zone, ar *area.Room) {
+	r := mud.NewRoom(ai.universe, 0, ar.Name + ".\n\n" + ar.Description)
+	r.SetZone(z)
+	if ar.Flags & area.RoomDark != 0 {
+		r.SetProperty("light", "0")
+	}
+	if ar.Sector == area.SectorInside || ar.Flags & area.RoomIndoors != 0 {
+		r.SetProperty("outdoors", "no")
+		r.SetFlag(mud.FlagIndoors, ar.Flags & area.RoomIndoors != 0)
+	} else {
+		r.SetProperty("outdoors", "yes")
+	}
+	r.SetFlag(mud.FlagPrivate, ar.Flags & (area.RoomPrivate|area.RoomSolitary) != 0)
+	r.SetFlag(mud.FlagSafe, ar.Flags & area.RoomSafe != 0)
+	r.SetFlag(mud.FlagNoTeleport, ar.Flags & area.RoomNoRecall != 0)
+	for _, extra := range(ar.Extras) {
+		for _, keyword := range(extra.Keywords) {
+			r.SetExtraDescription(keyword, extra.Text)
+		}
+	}
+	ai.rooms[ar.Vnum] = r
+}
+
+// Exits 0-5 are north, east, south, west, up, down
+func oppositeDirection(dir int) int {
+	if dir >= 4 {
+		return 9 - dir
+	}
+	return (dir + 2) % 4
+}
+
+func findExit(ar *area.Room, dir int) *area.Exit {
+	for i := range(ar.Exits) {
+		if ar.Exits[i].Direction == dir { return &ar.Exits[i] }
+	}
+	return nil
+}
+
+func (ai *areaImport) keyHandle(vnum int) string {
+	if key, ok := ai.objects[vnum]; ok && len(key.Keywords) > 0 {
+		return key.Keywords[0]
+	}
+	return ""
+}
+
+/*
+ connect links a room's exits. An exit with a matching one back is a
+ two-way connection, made once from the room with the lower vnum.
+ */
+func (ai *areaImport) connect(a *area.Area, ar *area.Room, byVnum map[int]*area.Room) {
+	for _, exit := range(ar.Exits) {
+		if exit.Direction > 5 {
+			continue
+		}
+		to, ok := ai.rooms[exit.ToRoom]
+		if !ok {
+			ai.warn(a, "room %d: exit %s leads to unknown room %d",
+				ar.Vnum, area.Directions[exit.Direction], exit.ToRoom)
+			continue
+		}
+		aName := area.Directions[exit.Direction]
+		bName := area.Directions[oppositeDirection(exit.Direction)]
+		var back *area.Exit
+		if other, ok := byVnum[exit.ToRoom]; ok {
+			back = findExit(other, oppositeDirection(exit.Direction))
+		}
+		if back != nil && back.ToRoom != ar.Vnum {
+			back = nil
+		}
+		if back != nil && exit.ToRoom < ar.Vnum {
+			continue
+		}
+
+		var creator mud.RoomConnCreator
+		if exit.Locks != 0 || (back != nil && back.Locks != 0) {
+			key := exit.Key
+			if key <= 0 && back != nil { key = back.Key }
+			state, ok := ai.doorStates[[2]int{ar.Vnum, exit.Direction}]
+			if !ok {
+				state = ai.doorStates[[2]int{exit.ToRoom, oppositeDirection(exit.Direction)}]
+			}
+			creator = mud.DoorRoomConnectCreator(aName, bName, ai.keyHandle(key), state)
+		} else {
+			creator = mud.SimpleRoomConnectCreator(aName, bName)
+		}
+		if back == nil {
+			creator = mud.WithExitOptions(creator, mud.ExitOptions{OneWay: true})
+		}
+		mud.ConnectWithConnCreator(creator)(ai.rooms[ar.Vnum], to)
+	}
+}
+
+/*
+ readDoorResets notes the states D resets give doors: 0 open, 1
+ closed and 2 locked.
+ */
+func (ai *areaImport) readDoorResets(a *area.Area) {
+	for _, reset := range(a.Resets) {
+		if reset.Command == "D" && len(reset.Args) >= 4 {
+			ai.doorStates[[2]int{reset.Args[1], reset.Args[2]}] =
+				mud.DoorState(reset.Args[3])
+		}
+	}
+}
+
+// place puts a new object from a reset in a room
+func (ai *areaImport) place(a *area.Area, reset area.Reset, o mud.PhysicalObject) {
+	r, ok := ai.rooms[reset.Args[3]]
+	if !ok {
+		ai.warn(a, "reset on line %d: no room %d", reset.Line, reset.Args[3])
+		return
+	}
+	o.SetRoom(r)
+	r.AddChild(o)
+}
+
+/*
+ applyResets places the mobiles and objects the area's M and O resets
+ name. Objects given to or put in others (G, E and P) and shuffled
+ exits (R) aren't supported.
+ */
+func (ai *areaImport) applyResets(a *area.Area) {
+	for _, reset := range(a.Resets) {
+		switch reset.Command {
+		case "M", "O":
+			if len(reset.Args) < 4 {
+				ai.warn(a, "reset on line %d is too short", reset.Line)
+			} else if m, ok := ai.mobiles[reset.Args[1]]; ok && reset.Command == "M" {
+				npc := NewAreaMobile(ai.universe, m)
+				npc.SetPrototypeName(prototypeName(m.Keywords, m.Vnum))
+				ai.place(a, reset, npc)
+			} else if o, ok := ai.objects[reset.Args[1]]; ok && reset.Command == "O" {
+				obj := NewAreaObject(ai.universe, o)
+				obj.SetPrototypeName(prototypeName(o.Keywords, o.Vnum))
+				ai.place(a, reset, obj)
+			} else {
+				ai.warn(a, "reset on line %d: unknown vnum %d", reset.Line, reset.Args[1])
+			}
+		case "D":
+		default:
+			ai.warn(a, "reset on line %d: %s resets aren't supported",
+				reset.Line, reset.Command)
+		}
+	}
+}
+
+/*
+ ImportAreas builds the rooms of the given areas, each as a zone, and
+ links their exits (which may lead between areas). It returns the
+ first room of the first area.
+ */
+func ImportAreas(universe *mud.Universe, areas []*area.Area) *mud.Room {
+	ai := &areaImport{universe: universe, rooms: make(map[int]*mud.Room),
+		objects: make(map[int]*area.Object), mobiles: make(map[int]*area.Mobile),
+		doorStates: make(map[[2]int]mud.DoorState)}
+	byVnum := make(map[int]*area.Room)
+	var first *mud.Room
+	for _, a := range(areas) {
+		z := mud.NewZone(universe, a.Name)
+		for _, ar := range(a.Rooms) {
+			ai.buildRoom(z, ar)
+			byVnum[ar.Vnum] = ar
+			if first == nil { first = ai.rooms[ar.Vnum] }
+		}
+		for _, o := range(a.Objects) { ai.objects[o.Vnum] = o }
+		for _, m := range(a.Mobiles) { ai.mobiles[m.Vnum] = m }
+		ai.readDoorResets(a)
+	}
+	for _, a := range(areas) {
+		for _, ar := range(a.Rooms) {
+			ai.connect(a, ar, byVnum)
+		}
+		ai.applyResets(a)
+		mud.Log("Imported area", a.Name, "with", len(a.Rooms), "rooms")
+	}
+	return first
+}
@@ -2,11 +2,19 @@ package main
 
 import ("fmt"; "mud"; "mud/simple")
 
+func init() {
+	mud.Prototypes["ball"] = func(u *mud.Universe) mud.PhysicalObject {
+		return NewBall(u, "&red;red&;")
+	}
+}
+
 func NewBall(universe *mud.Universe, description string) *simple.PhysicalObject {
 	ball := simple.NewPhysicalObject(universe)
 	ball.SetDescription(fmt.Sprintf("A %s ball",description))
 	ball.SetVisible(true)
 	ball.SetCarryable(true)
+	ball.SetLongDescription("A rubber ball, scuffed from years of play.")
 	ball.SetTextHandles("ball", "red ball")
+	ball.SetValue(2)
 	return ball
 }
\ No newline at end of file
@@ -0,0 +1,34 @@
+package main
+
+import ("mud"; "mud/simple")
+
+func init() {
+	mud.Prototypes["chest"] = func(u *mud.Universe) mud.PhysicalObject {
+		return NewChest(u)
+	}
+	mud.Prototypes["bag"] = func(u *mud.Universe) mud.PhysicalObject {
+		return NewBag(u)
+	}
+}
+
+// NewChest makes a closed chest, too heavy to carry, which the brass key locks
+func NewChest(universe *mud.Universe) *simple.Container {
+	chest := simple.NewContainer(universe, 10)
+	chest.SetDescription("A wooden chest")
+	chest.SetTextHandles("chest", "wooden chest")
+	chest.SetContainerState(mud.DoorClosed)
+	chest.SetKeyHandle("brass key")
+	chest.SetWeight(40)
+	chest.SetBulk(8)
+	chest.SetValue(60)
+	return chest
+}
+
+func NewBag(universe *mud.Universe) *simple.Container {
+	bag := simple.NewContainer(universe, 4)
+	bag.SetDescription("A canvas bag")
+	bag.SetCarryable(true)
+	bag.SetTextHandles("bag", "canvas bag")
+	bag.SetValue(8)
+	return bag
+}
@@ -4,6 +4,14 @@ import ("mud"
 	"mud/simple"
 	"strconv")
 
+func init() {
+	mud.Prototypes["clock"] = func(u *mud.Universe) mud.PhysicalObject {
+		clock := NewClock(u)
+		u.Add(clock)
+		return clock
+	}
+}
+
 func updateDescription(time int, p *simple.PhysicalObject) {
 	if time % 100 == 0 {
 		p.SetDescription("A large clock reading " + strconv.Itoa(time))
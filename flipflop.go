@@ -2,19 +2,12 @@ package main
 
 import ("mud"
 	"mud/simple"
-	"strings"
-	"fmt"
-	"strconv")
+	"strings")
 
 func init() {
-	mud.Loaders["flipFlop"] = LoadFlipFlop
-	mud.PersistentKeys["flipFlop"] = []string{ "id", "bling" }
-}
-
-type flipFlopPersister struct {
-	mud.Persister
-	npc *simple.NPC
-	universe *mud.Universe
+	mud.Prototypes["flip-flop"] = func(u *mud.Universe) mud.PhysicalObject {
+		return NewFlipFlop(u)
+	}
 }
 
 func ffHandleSay(s mud.Stimulus, n *simple.NPC) {
@@ -34,43 +27,14 @@ func ffHandleSay(s mud.Stimulus, n *simple.NPC) {
 	}
 }
 
-func (f flipFlopPersister) PersistentValues() map[string]interface{} {
-	vals := make(map[string]interface{})
-	if(f.npc.ID() > 0) {
-		vals["id"] = strconv.Itoa(f.npc.ID())
-	}
-	vals["bling"] = f.npc.Meta["lastText"]
-	return vals
-}
-func (f *flipFlopPersister) Save() string {
-	outID := f.universe.Store.SaveStructure("flipFlop",f.PersistentValues())
-	if(f.npc.ID() == 0) {
-		id, _ := strconv.Atoi(outID)
-		f.npc.SetId(id)
-	}
-	return outID
-}
-
-func (f *flipFlopPersister) DBFullName() string {
-	return fmt.Sprintf("flipFlop:%d", f.npc.ID())
-}
-
 func NewFlipFlop(u *mud.Universe) *simple.NPC {
 	ff := simple.NewNPC(u)
-	persister := new(flipFlopPersister)
-
-	persister.npc = ff
-	persister.universe = u
-
 	ff.SetUniverse(u)
 	ff.AddStimHandler("say", ffHandleSay)
 	ff.Meta["lastText"] = "Unchanged."
 	ff.SetDescription(ff.Meta["lastText"].(string))
 	ff.SetVisible(true)
 
-	u.Add(ff)
-	u.Add(persister)
-
 	go mud.StimuliLoop(ff)
 
 	return ff
@@ -82,15 +46,3 @@ func BuildFFInRoom(u *mud.Universe, p *mud.Player, args []string) {
 	room := p.Room()
 	room.AddChild(ff)
 }
-
-func LoadFlipFlop(u *mud.Universe, id int) interface{} {
-	var ok bool
-	vals := u.Store.LoadStructure(mud.PersistentKeys["flipFlop"],
-		mud.FieldJoin(":","flipFlop",strconv.Itoa(id)))
-	ff := NewFlipFlop(u)
-	ff.SetId(id)
-	ff.Meta["lastText"], ok = vals["bling"].(string)
-	ff.SetDescription(ff.Meta["lastText"].(string))
-	if !ok { panic("flipFlop:bling not string") }
-	return ff
-}
\ No newline at end of file
@@ -1,7 +1,8 @@
 package main
 
 import ("mud"
-	"fmt")
+	"fmt"
+	"strconv")
 
 type Fruit struct {
 	mud.PhysicalObject
@@ -10,10 +11,14 @@ type Fruit struct {
 	room *mud.Room
 	name string
 	ping chan int
+	stopped chan bool
 	stage LifeStage
 	lastChange int
+	grown float64
 	visible bool
 	hasMadePlant bool
+	count int
+	id int
 }
 
 var fruitStages map[int]LifeStage
@@ -31,6 +36,11 @@ func init() {
 	addLs(rotten, fruitStages)
 	addLs(pit, fruitStages)
 	addLs(defunct, fruitStages)
+
+	mud.Loaders["fruit"] = LoadFruit
+	mud.PersistentKeys["fruit"] = []string{ "id", "name", "stage", "grown", "count" }
+
+	mud.PlayerPerceptions["taste"] = func(p mud.Player, s mud.Stimulus) bool { return true }
 }
 
 func (f Fruit) Visible() bool { return f.visible }
@@ -42,20 +52,102 @@ func (f Fruit) Description() string {
 	return fmt.Sprintf("A(n) %s %s", f.stage.Name, f.name);
 }
 
+func (f Fruit) Weight() int { return 1 }
+func (f Fruit) Bulk() int { return 1 }
+
+// Value falls as the fruit passes its best
+func (f Fruit) Value() mud.Currency {
+	switch f.stage.Name {
+	case "underripe":
+		return 10
+	case "ripe":
+		return 15
+	case "rotten":
+		return 1
+	}
+	return 0
+}
+
+// Fruit of the same kind and ripeness stack, and ripen together
+func (f Fruit) StackKey() string { return f.name + " " + f.stage.Name }
+func (f Fruit) Count() int { return f.count }
+func (f *Fruit) SetCount(n int) { f.count = n }
+func (f *Fruit) Split(n int) mud.Stackable {
+	part := MakeFruit(f.universe, f.name)
+	part.stage, part.lastChange, part.grown, part.count = f.stage, f.lastChange, f.grown, n
+	f.count -= n
+	return part
+}
+
 func (f *Fruit) SetRoom(r *mud.Room) { f.room = r }
 func (f Fruit) Room() *mud.Room { return f.room }
 
 type FruitTasteStimulus struct {
 	mud.Stimulus
 	f *Fruit
+	eater *mud.Player
+	stage string
+}
+
+func (s FruitTasteStimulus) StimType() string { return "taste" }
+func (s FruitTasteStimulus) Description(p mud.Perceiver) string {
+	if player, ok := p.(*mud.Player); ok && player.ID() == s.eater.ID() {
+		switch s.stage {
+		case "underripe":
+			return "It is hard and sour.\n"
+		case "ripe":
+			return "It is sweet and juicy.\n"
+		}
+		return "It tastes foul, and your stomach turns.\n"
+	}
+	switch s.stage {
+	case "underripe":
+		return s.eater.Name() + " puckers at the sour " + s.f.name + ".\n"
+	case "ripe":
+		return s.eater.Name() + " savours the " + s.f.name + ".\n"
+	}
+	return s.eater.Name() + " gags on the rotten " + s.f.name + ".\n"
+}
+
+/*
+ Consume lets fruit be eaten until it has rotted down to its pit.
+ Ripe fruit is best, and rotten fruit makes the eater sick.
+ */
+func (f *Fruit) Consume(p *mud.Player, verb string) (mud.Effect, bool) {
+	effect := mud.Effect{UsedUp: true,
+		Stimulus: FruitTasteStimulus{f: f, eater: p, stage: f.stage.Name}}
+	if verb != "eat" {
+		return effect, false
+	}
+	switch f.stage.Name {
+	case "underripe":
+		effect.Health = 2
+	case "ripe":
+		effect.Health = 10
+		effect.Buffs = []mud.Buff{{Name: "well fed", Stat: "strength",
+			Amount: 1, Minutes: 60}}
+	case "rotten":
+		effect.Health = -5
+		effect.Buffs = []mud.Buff{{Name: "sick", Stat: "strength",
+			Amount: -3, Minutes: 120}}
+	default:
+		return effect, false
+	}
+	return effect, true
 }
 
 func (f Fruit) Ping() chan int { return f.ping }
+func (f Fruit) Stopped() chan bool { return f.stopped }
 func (f Fruit) LastChange() int { return f.lastChange }
 func (f Fruit) LifeStages() map[int]LifeStage { return fruitStages }
 func (f Fruit) Stage() LifeStage { return f.stage }
 func (f *Fruit) SetStage(l LifeStage) { f.stage = l }
 func (f *Fruit) SetStageChanged(now int) { f.lastChange = now }
+func (f Fruit) Grown() float64 { return f.grown }
+func (f *Fruit) SetGrown(grown float64) { f.grown = grown }
+
+// Destroyed stops the fruit aging, once it is merged away or vanishes
+func (f *Fruit) Destroyed() { close(f.stopped) }
 
 func BecomePlant(atl AgingTimeListener) {
 	f := atl.(*Fruit)
@@ -66,7 +158,7 @@ func BecomePlant(atl AgingTimeListener) {
 		f.Room().AddChild(p)
 		p.SetRoom(f.Room())
 		
-		p.Room().Actions() <- mud.VanishAction{Target: f}
+		p.Room().Act(mud.VanishAction{Target: f})
 	}
 }
 
@@ -75,12 +167,62 @@ func MakeFruit(u *mud.Universe, name string) *Fruit {
 	f.universe = u
 	f.name = name
 	f.ping = make(chan int)
+	f.stopped = make(chan bool)
 	f.stage = fruitStages[0]
 	f.visible = true
+	f.count = 1
 
 	u.Add(f)
 
 	go AgeLoop(f)
 
 	return f
-}
\ No newline at end of file
+}
+// A stack of fruit is saved as one record, with its count
+func (f *Fruit) PersistentValues() map[string]interface{} {
+	vals := make(map[string]interface{})
+	if f.id > 0 {
+		vals["id"] = strconv.Itoa(f.id)
+	}
+	vals["name"] = f.name
+	vals["stage"] = strconv.Itoa(f.stage.StageNo)
+	vals["grown"] = strconv.FormatFloat(f.grown, 'f', -1, 64)
+	vals["count"] = strconv.Itoa(f.count)
+	return vals
+}
+
+func (f *Fruit) Save() string {
+	outID := f.universe.Store.SaveStructure("fruit", f.PersistentValues())
+	if f.id == 0 {
+		f.id, _ = strconv.Atoi(outID)
+	}
+	return outID
+}
+
+func (f *Fruit) DBFullName() string {
+	return fmt.Sprintf("fruit:%d", f.id)
+}
+
+func LoadFruit(u *mud.Universe, id int) interface{} {
+	vals := u.Store.LoadStructure(mud.PersistentKeys["fruit"],
+		mud.FieldJoin(":", "fruit", strconv.Itoa(id)))
+	name, ok := vals["name"].(string)
+	if !ok {
+		return nil
+	}
+	f := MakeFruit(u, name)
+	f.id = id
+	stage, _ := vals["stage"].(string)
+	if n, err := strconv.Atoi(stage); err == nil {
+		if ls, ok := fruitStages[n]; ok {
+			f.stage = ls
+		}
+	}
+	grown, _ := vals["grown"].(string)
+	f.grown, _ = strconv.ParseFloat(grown, 64)
+	count, _ := vals["count"].(string)
+	if n, err := strconv.Atoi(count); err == nil && n > 0 {
+		f.count = n
+	}
+	return f
+}
@@ -0,0 +1,40 @@
+package main
+
+import ("mud"; "mud/simple")
+
+func init() {
+	mud.Prototypes["leather cap"] = func(u *mud.Universe) mud.PhysicalObject {
+		leatherCap := newGear(u, "A leather cap", "cap", "leather cap")
+		leatherCap.SetSlots("head")
+		leatherCap.SetValue(12)
+		return leatherCap
+	}
+	mud.Prototypes["short sword"] = func(u *mud.Universe) mud.PhysicalObject {
+		sword := newGear(u, "A short sword", "sword", "short sword")
+		sword.SetSlots("wield")
+		sword.SetWeight(4)
+		sword.SetBulk(2)
+		sword.SetValue(40)
+		return sword
+	}
+	mud.Prototypes["belt of strength"] = func(u *mud.Universe) mud.PhysicalObject {
+		belt := newGear(u, "A broad belt studded with iron", "belt",
+			"belt of strength")
+		belt.SetLongDescription("A broad leather belt studded with iron. " +
+			"Your back straightens as you hold it.")
+		belt.SetSlots("waist")
+		belt.SetModifier("strength", 5)
+		belt.SetWeight(2)
+		belt.SetValue(150)
+		return belt
+	}
+}
+
+func newGear(universe *mud.Universe, description string, handles... string) *simple.PhysicalObject {
+	gear := simple.NewPhysicalObject(universe)
+	gear.SetDescription(description)
+	gear.SetVisible(true)
+	gear.SetCarryable(true)
+	gear.SetTextHandles(handles...)
+	return gear
+}
@@ -0,0 +1,19 @@
+package main
+
+import ("fmt"; "mud"; "mud/simple")
+
+func init() {
+	mud.Prototypes["brass key"] = func(u *mud.Universe) mud.PhysicalObject {
+		return NewKey(u, "brass")
+	}
+}
+
+func NewKey(universe *mud.Universe, metal string) *simple.PhysicalObject {
+	key := simple.NewPhysicalObject(universe)
+	key.SetDescription(fmt.Sprintf("A small %s key", metal))
+	key.SetVisible(true)
+	key.SetCarryable(true)
+	key.SetTextHandles("key", metal + " key")
+	key.SetValue(5)
+	return key
+}
@@ -0,0 +1,25 @@
+package main
+
+import ("mud"; "mud/simple")
+
+func init() {
+	mud.Prototypes["lantern"] = func(u *mud.Universe) mud.PhysicalObject {
+		return NewLantern(u)
+	}
+}
+
+func NewLantern(universe *mud.Universe) *simple.PhysicalObject {
+	lantern := simple.NewPhysicalObject(universe)
+	lantern.SetDescription("An oil lantern")
+	lantern.SetVisible(true)
+	lantern.SetCarryable(true)
+	lantern.SetLightSource(true)
+	lantern.SetSlots("hold")
+	lantern.SetTextHandles("lantern", "oil lantern")
+	lantern.SetWeight(3)
+	lantern.SetBulk(2)
+	lantern.SetValue(25)
+	lantern.SetDurability(mud.Durability{Max: 40, WearPerUse: 1,
+		Message: "falls to pieces"})
+	return lantern
+}
@@ -2,16 +2,17 @@ package main
 
 import ("os"
 	"net"
+	"strings"
 	"math/rand"
 	"time"
 	"flag"
 	"mud"
+	"strconv"
 	"fmt")
 
 type NamePrompt struct {
 	mud.ConnectionState
 	universe *mud.Universe
-	StartRoom *mud.Room
 	PlayerRemoveChan chan *mud.Player
 }
 
@@ -25,7 +26,7 @@ func (n *NamePrompt) Respond(c *mud.UserConnection) bool {
 	c.Data["playerName"] = playerName
 
 	newP := n.universe.PlayerFromUserConn(c)
-	mud.PlacePlayerInRoom(n.StartRoom, newP)
+	mud.PlacePlayerInRoom(n.universe.StartRoom, newP)
 	mud.Look(newP, []string{})
 	
 	go newP.ReadLoop(n.PlayerRemoveChan)
@@ -35,34 +36,146 @@ func (n *NamePrompt) Respond(c *mud.UserConnection) bool {
 	return false
 }
 
+/*
+ worldFileCommand exports the world in Redis to a file, or imports one
+ in its place. Players are kept.
+ */
+func worldFileCommand(dbNo int, args []string) int {
+	if len(args) != 2 || (args[0] != "export" && args[0] != "import") {
+		flag.Usage()
+		return 2
+	}
+	universe := mud.NewUniverse(dbNo)
+	var err error
+	if args[0] == "export" {
+		var f *os.File
+		if f, err = os.Create(args[1]); err == nil {
+			err = mud.ExportWorld(universe, f)
+			f.Close()
+		}
+	} else {
+		var f *os.File
+		if f, err = os.Open(args[1]); err == nil {
+			err = mud.ImportWorld(universe, f)
+			f.Close()
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, args[0] + ":", err)
+		return 1
+	}
+	return 0
+}
+
+/*
+ seedFlag is -seed, to seed from seed.go, or -seed=[dir] to seed from
+ a directory of world files.
+ */
+type seedFlag struct {
+	seed bool
+	dir string
+}
+
+func (s *seedFlag) String() string {
+	if s == nil { return "" }
+	return s.dir
+}
+func (s *seedFlag) IsBoolFlag() bool { return true }
+func (s *seedFlag) Set(value string) error {
+	if b, err := strconv.ParseBool(value); err == nil {
+		s.seed, s.dir = b, ""
+	} else {
+		s.seed, s.dir = true, value
+	}
+	return nil
+}
+
 func main() {
 	flagPort := flag.Int("port", 3000,
 		"port to listen for mud clients")
-	flagUseSeed := flag.Bool("seed", false, 
-		"flush DB and seed universe with prototype's seed.go")
+	flagUseSeed := new(seedFlag)
+	flag.Var(flagUseSeed, "seed",
+		"flush DB and seed universe with prototype's seed.go, or -seed=[dir] " +
+		"to seed from the .world files in dir")
 	flagUseLoad := flag.Bool("load", true, 
 		"load objects from DB")
 	flagSpeedupFactor := flag.Float64("speedup", 1.0,
 		"factor to speed up heartbeat loop (2.0 means heartbeats come twice as often)")
+	flagMinuteTicks := flag.Int("minute", 1000,
+		"heartbeats per game minute (1000 is one game minute per second)")
+	flagResetTicks := flag.Int("reset", 60000,
+		"heartbeats between zone resets (0 turns resets off)")
 	flagRedisDbNo := flag.Int("dbno", 3,
 		"redis DB# to load from/seed into")
+	flagSocials := flag.String("socials", "socials.txt",
+		"file of predefined socials/emotes")
+	flagBuilders := flag.String("builders", "",
+		"comma-separated names of players who may build")
+	flagAdmins := flag.String("admins", "",
+		"comma-separated names of players who may build and review changes")
+	flagAreas := flag.String("areas", "",
+		"comma-separated Diku/ROM area files to seed from and take prototypes from")
 	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gomud [flags], gomud [flags] export [file]" +
+			" or gomud [flags] import [file]")
 		flag.PrintDefaults()
 	}
 	flag.Parse()
 	mud.Log("program args: ", os.Args)
 
+	if flag.NArg() > 0 {
+		os.Exit(worldFileCommand(*flagRedisDbNo, flag.Args()))
+	}
+
 	rand.Seed(time.Now().Unix())
+	for _, name := range strings.Split(*flagBuilders, ",") {
+		if name != "" { mud.BuilderNames[name] = true }
+	}
+	for _, name := range strings.Split(*flagAdmins, ",") {
+		if name != "" { mud.AdminNames[name] = true }
+	}
+	if socials, serr := mud.LoadSocials(*flagSocials); serr == nil {
+		mud.RegisterSocials(socials)
+		mud.Log("Loaded", len(socials), "socials")
+	} else {
+		mud.Log("Error loading socials", serr)
+	}
+	areas, aerr := LoadAreaFiles(*flagAreas)
+	if aerr != nil {
+		fmt.Fprintln(os.Stderr, "areas:", aerr)
+		os.Exit(1)
+	}
+	for _, a := range(areas) {
+		RegisterAreaPrototypes(a)
+	}
+	// Check world files before anything is flushed
+	var world *mud.WorldDef
+	if flagUseSeed.seed && flagUseSeed.dir != "" {
+		var werr error
+		if world, werr = mud.LoadWorldDir(flagUseSeed.dir); werr == nil {
+			werr = world.Validate()
+		}
+		if werr != nil {
+			fmt.Fprintln(os.Stderr, "seed:", werr)
+			os.Exit(1)
+		}
+	}
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d",*flagPort))
 	universe := mud.NewUniverse(*flagRedisDbNo)
 	universe.Maker = BuildFFInRoom
 	playerRemoveChan := make(chan *mud.Player)
 
 	var theRoom *mud.Room
-	if *flagUseSeed {
+	if flagUseSeed.seed {
 		mud.Log("Seeding Universe")
 		universe.ClearDB()
-		theRoom = InitUniverse(universe)
+		if world != nil {
+			theRoom, _ = mud.SeedWorld(universe, world)
+		} else if len(areas) > 0 {
+			theRoom = ImportAreas(universe, areas)
+		} else {
+			theRoom = InitUniverse(universe)
+		}
 	} else if *flagUseLoad {
 		mud.Log("Loading Universe")
 		theRoom = LoadUniverse(universe)
@@ -70,6 +183,20 @@ func main() {
 	}
 
 	mud.Log("len(rooms) =",len(universe.Rooms))
+	universe.StartRoom = theRoom
+	if theRoom != nil {
+		for _, conflict := range mud.InferCoordinates(theRoom) {
+			mud.Log("[WARN] coordinate conflict:", conflict)
+		}
+	}
+
+	mud.LoadJournal(universe)
+	clock := mud.LoadGameClock(universe, *flagMinuteTicks)
+	mud.Log("Game time is", clock.Now())
+	mud.StartWeather(universe)
+	mud.StartEffects(universe)
+	mud.StartDecay(universe)
+	mud.StartResets(universe, *flagResetTicks)
 
 	go universe.HandlePersist()
 	go universe.HeartbeatLoop(*flagSpeedupFactor)
@@ -84,7 +211,6 @@ func main() {
 			if aerr == nil {
 				namePrompt := new(NamePrompt)
 				namePrompt.universe = universe
-				namePrompt.StartRoom = theRoom
 				namePrompt.PlayerRemoveChan = playerRemoveChan
 				mud.NewUserConnection(conn, namePrompt)
 			} else {
@@ -7,56 +7,110 @@ func init() {
 	mud.GlobalCommands["rewrite"] = Rewrite
 }
 
+/*
+ Pioneer builds a new room through a new exit. Compass directions
+ get their opposite as the way back; any other exit name needs one:
+
+	pioneer north
+	pioneer climb ladder = climb down
+ */
 func Pioneer(p *mud.Player, args[] string) {
-	direction := args[0]
+	if !p.Room().CanEdit(p) {
+		p.WriteString("You can't build here.\n")
+		return
+	}
+	if len(args) < 1 {
+		p.WriteString("Pioneer usage: pioneer [exit] or " +
+			"pioneer [exit] = [return exit].\n")
+		return
+	}
+	direction, returnName := splitPioneerArgs(args)
 
 	mud.Log("Pioneer",args)
-	
+
+	if returnName == "" {
+		opposite, ok := mud.OppositeExit(direction)
+		if !ok {
+			p.WriteString("No return exit known for '" + direction +
+				"', use pioneer [exit] = [return exit].\n")
+			return
+		}
+		returnName = opposite
+	}
+
 	p.Room().WithExit(direction, func(rei *mud.RoomExitInfo) {
 		p.WriteString("That exit already exists.\n")
 		return
 	}, func() {
-		BuildPioneerRoom(p, direction)
+		BuildPioneerRoom(p, direction, returnName)
 	})
 }
 
-func BuildPioneerRoom(p *mud.Player, direction string) {
-	var roomConn *mud.SimpleRoomConnection
+func splitPioneerArgs(args []string) (string, string) {
+	parts := strings.SplitN(strings.Join(args, " "), "=", 2)
+	direction := strings.TrimSpace(parts[0])
+	if len(parts) == 1 {
+		return direction, ""
+	}
+	return direction, strings.TrimSpace(parts[1])
+}
+
+func BuildPioneerRoom(p *mud.Player, direction string, returnName string) {
+	here, hasCoords := p.Room().Coords()
+	offset, isCompass := mud.CompassOffset(direction)
+	if hasCoords && isCompass {
+		if other := mud.RoomAt(p.Universe, here.Add(offset)); other != nil {
+			p.WriteString("There is already a room " + direction +
+				" of here, at " + here.Add(offset).String() + ".\n")
+			return
+		}
+	}
 	newRoom := mud.NewRoom(p.Universe,
 		0,
 		"A default room text.")
-	switch direction {
-	case "east":
-		roomConn = mud.ConnectEastWest(p.Room(), newRoom)
-	case "west":
-		roomConn = mud.ConnectEastWest(newRoom, p.Room())
-	case "north":
-		roomConn = mud.ConnectNorthSouth(p.Room(), newRoom)
-	case "south":
-		roomConn = mud.ConnectNorthSouth(newRoom, p.Room())
-	default:
-		p.WriteString("Pioneering only east/west/north/south supported.\n")
-		return
-	}
-	p.Universe.Add(roomConn)
+	newRoom.SetZone(p.Room().Zone())
+	mud.RecordCreated(p, newRoom)
+	connect := mud.ConnectWithConnCreator(
+		mud.SimpleRoomConnectCreator(direction, returnName))
+	rc := connect(p.Room(), newRoom)
+	rc.Save()
+	mud.RecordLinked(p, rc)
 }
 
+/*
+ Rewrite changes the room's text, or with a time of day sets the text
+ added to the description at that time (blank to remove it):
+
+	rewrite all A dusty road.
+	rewrite night Crickets chirp in the dark.
+ */
 func Rewrite(p *mud.Player, args[] string) {
+	if !p.Room().CanEdit(p) {
+		p.WriteString("You can't build here.\n")
+		return
+	}
+	if len(args) < 1 {
+		p.WriteString("Rewrite usage: rewrite [all|append|prepend|" +
+			"dawn|day|dusk|night] [text].\n")
+		return
+	}
 	subCommand := args[0]
 	line := strings.Join(args[1:], " ")
 	r := p.Room()
 	switch subCommand {
 	case "all":
-		r.SetText(line)
+		mud.EditRoomText(p, r, line)
 	case "append":
 		roomText := r.Text()
 		roomText = strings.Join([]string{roomText, line}, "\r\n")
-		r.SetText(roomText)
+		mud.EditRoomText(p, r, roomText)
 	case "prepend":
 		roomText := r.Text()
 		roomText = strings.Join([]string{line, roomText}, "\r\n")
-		r.SetText(roomText)
+		mud.EditRoomText(p, r, roomText)
+	case mud.Dawn, mud.Day, mud.Dusk, mud.Night:
+		mud.EditRoomProperty(p, r, "desc:" + subCommand, line)
 	default:
 		p.WriteString("Rewrite subcommand not recognized.\n")
 	}
-}
\ No newline at end of file
+}
@@ -1,7 +1,9 @@
 package main
 
 import ("mud"
-	"fmt")
+	"mud/simple"
+	"fmt"
+	"strconv")
 
 type Plant struct {
 	mud.PhysicalObject
@@ -10,9 +12,14 @@ type Plant struct {
 	room *mud.Room
 	name string
 	ping chan int
+	stopped chan bool
 	stage LifeStage
 	lastChange int
+	grown float64
 	hasMadeTree bool
+	id int
+	prototype string
+	defaults map[string]string
 }
 
 var plantStages map[int]LifeStage
@@ -30,6 +37,12 @@ func init() {
 	addLs(stalk, plantStages)
 	addLs(miniTree, plantStages)
 	addLs(defunct, plantStages)
+
+	mud.Loaders["plant"] = LoadPlant
+	mud.PersistentKeys["plant"] = []string{ "id", "prototype", "fields" }
+	mud.Prototypes["peach plant"] = func(u *mud.Universe) mud.PhysicalObject {
+		return MakePlant(u, "peach")
+	}
 }
 
 func (p Plant) Visible() bool { 
@@ -48,11 +61,17 @@ func (p *Plant) SetRoom(r *mud.Room) { p.room = r }
 func (p Plant) Room() *mud.Room { return p.room }
 
 func (p *Plant) Ping() chan int { return p.ping }
+func (p *Plant) Stopped() chan bool { return p.stopped }
 func (p Plant) LastChange() int { return p.lastChange }
 func (p Plant) LifeStages() map[int]LifeStage { return plantStages }
 func (p Plant) Stage() LifeStage { return p.stage }
 func (p *Plant) SetStage(l LifeStage) { p.stage = l }
 func (p *Plant) SetStageChanged(now int) { p.lastChange = now }
+func (p Plant) Grown() float64 { return p.grown }
+func (p *Plant) SetGrown(grown float64) { p.grown = grown }
+
+// Destroyed stops the plant aging, once it withers away
+func (p *Plant) Destroyed() { close(p.stopped) }
 
 func BecomeTree(atl AgingTimeListener) {
 	p := atl.(*Plant)
@@ -63,7 +82,8 @@ func BecomeTree(atl AgingTimeListener) {
 			t := MakeFruitTree(p.universe, p.name)
 			p.Room().AddChild(t)
 		} else {
-			p.Room().Actions() <- mud.VanishAction{Target: p}
+			p.Room().Act(mud.VanishAction{Target: p,
+				Message: "withers away"})
 		}
 	}
 }
@@ -73,6 +93,7 @@ func MakePlant(u *mud.Universe, name string) *Plant {
 	p.universe = u
 	p.name = name
 	p.ping = make(chan int)
+	p.stopped = make(chan bool)
 	p.stage = plantStages[0]
 
 	u.Add(p)
@@ -80,4 +101,80 @@ func MakePlant(u *mud.Universe, name string) *Plant {
 	go AgeLoop(p)
 
 	return p
-}
\ No newline at end of file
+}
+
+/*
+ Plants persist as simple objects do: the prototype which made them
+ and the fields changed since, or every field if they grew from fruit.
+ */
+func (p *Plant) fields() map[string]string {
+	return map[string]string{
+		"name": p.name,
+		"stage": strconv.Itoa(p.stage.StageNo),
+		"grown": strconv.FormatFloat(p.grown, 'f', -1, 64),
+	}
+}
+
+func (p *Plant) setField(name string, value string) {
+	switch name {
+	case "name":
+		p.name = value
+	case "stage":
+		n, _ := strconv.Atoi(value)
+		if ls, ok := plantStages[n]; ok {
+			p.stage = ls
+		}
+	case "grown":
+		p.grown, _ = strconv.ParseFloat(value, 64)
+	}
+}
+
+func (p Plant) PrototypeName() string { return p.prototype }
+
+// SetPrototypeName marks p as made by a prototype, taking its fields as the defaults
+func (p *Plant) SetPrototypeName(name string) {
+	p.prototype = name
+	p.defaults = p.fields()
+}
+
+func (p *Plant) PersistentValues() map[string]interface{} {
+	vals := make(map[string]interface{})
+	if p.id > 0 {
+		vals["id"] = strconv.Itoa(p.id)
+	}
+	vals["prototype"] = p.prototype
+	vals["fields"] = simple.Overrides(p.fields(), p.defaults)
+	return vals
+}
+
+func (p *Plant) Save() string {
+	outID := p.universe.Store.SaveStructure("plant", p.PersistentValues())
+	if p.id == 0 {
+		p.id, _ = strconv.Atoi(outID)
+	}
+	return outID
+}
+
+func (p *Plant) DBFullName() string {
+	return fmt.Sprintf("plant:%d", p.id)
+}
+
+func LoadPlant(u *mud.Universe, id int) interface{} {
+	vals := u.Store.LoadStructure(mud.PersistentKeys["plant"],
+		mud.FieldJoin(":", "plant", strconv.Itoa(id)))
+	if _, ok := vals["id"]; !ok {
+		return nil
+	}
+	var p *Plant
+	if o, ok := simple.MakeFromPrototype(u, vals); ok {
+		if p, ok = o.(*Plant); !ok {
+			mud.Log("[warn] prototype", vals["prototype"], "doesn't make a plant")
+			return o
+		}
+	} else {
+		p = MakePlant(u, "")
+	}
+	p.id = id
+	simple.ApplyFields(vals, p.setField)
+	return p
+}
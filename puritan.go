@@ -2,8 +2,15 @@ package main
 
 import ("mud"
 	"mud/simple"
+	"strconv"
 	"strings")
 
+func init() {
+	mud.Prototypes["puritan"] = func(u *mud.Universe) mud.PhysicalObject {
+		return NewPuritan(u)
+	}
+}
+
 func ContainsAny(s string, subs ...string) bool {
 	for _,sub := range(subs) {
 		if(strings.Contains(s, sub)) {
@@ -29,10 +36,31 @@ func puritanHandleSay(s mud.Stimulus, n *simple.NPC) {
 	}
 }
 
+func puritanHandleSocial(s mud.Stimulus, n *simple.NPC) {
+	scast, ok := s.(mud.SocialStimulus)
+	if !ok {
+		panic("Puritan should only receive SocialStimulus")
+	}
+	if scast.Target() != n {
+		return
+	}
+	switch scast.Social().Name {
+	case "hug", "kiss", "poke":
+		n.Room().Broadcast(mud.TalkerSay(n,
+			"Unhand me, " + scast.Actor().Name() + "!"))
+	case "bow":
+		n.Room().Broadcast(mud.TalkerSay(n,
+			"How very courteous, " + scast.Actor().Name() + "."))
+	}
+}
+
 func NewPuritan(universe *mud.Universe) *simple.NPC {
 	puritan := simple.NewNPC(universe)
 	puritan.AddStimHandler("say", puritanHandleSay)
+	puritan.AddStimHandler("social", puritanHandleSocial)
 	puritan.SetVisible(true)
+	puritan.SetName("Penelope")
+	puritan.SetTextHandles("penelope", "puritan")
 	puritan.SetDescription("Penelope Proper")
 	puritan.SetCarryable(false)
 	puritan.AddCommand("buy", buy)
@@ -60,14 +88,36 @@ func (p PurchaseAction) Exec() {
 			p.buyer.WriteString("Thanks for your purchase!\n\r")
 		} else {
 			p.buyer.WriteString("You do not have enough space.\n\r")
+			mud.Destroy(p.buyer.Universe, p.saleObject)
 		}
 	} else {
 		p.buyer.WriteString("You do not have enough money.\n\r")
+		mud.Destroy(p.buyer.Universe, p.saleObject)
 	}
 }
 
+// The most fruit one purchase may buy, which keeps its price in range
+const maxPurchase = mud.MAX_INVENTORY
+
+// buy [number] [fruit] buys one fruit, or a stack of them
 func buy(p *mud.Player, args[] string) {
+	count := 1
+	if len(args) > 1 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			if n < 1 || n > maxPurchase {
+				p.WriteString("You can buy from 1 to " +
+					strconv.Itoa(maxPurchase) + " at once.\n")
+				return
+			}
+			count, args = n, args[1:]
+		}
+	}
+	if len(args) == 0 {
+		p.WriteString("Buy usage: buy [number] [fruit].\n")
+		return
+	}
 	fruit := MakeFruit(p.Universe, args[0])
-	action := PurchaseAction{ price: 10, buyer: p, saleObject: fruit }
-	p.Room().Actions() <- action
+	fruit.SetCount(count)
+	action := PurchaseAction{ price: mud.ValueOf(fruit), buyer: p, saleObject: fruit }
+	p.Room().Act(action)
 }
\ No newline at end of file
@@ -0,0 +1,43 @@
+package main
+
+import ("mud"; "mud/simple")
+
+func init() {
+	mud.Prototypes["healing potion"] = func(u *mud.Universe) mud.PhysicalObject {
+		return NewRemedy(u, "drink", "A small vial of red liquid",
+			mud.Effect{Message: "Warmth spreads through your limbs.",
+				Health: 30, UsedUp: true},
+			"potion", "vial", "healing potion")
+	}
+	mud.Prototypes["bandage"] = func(u *mud.Universe) mud.PhysicalObject {
+		return NewRemedy(u, "use", "A roll of linen bandage",
+			mud.Effect{Message: "You bind up your scrapes and bruises.",
+				Health: 15, UsedUp: true},
+			"bandage", "linen bandage")
+	}
+	mud.Prototypes["loaf of bread"] = func(u *mud.Universe) mud.PhysicalObject {
+		bread := NewRemedy(u, "eat", "A loaf of bread",
+			mud.Effect{Message: "It is plain but filling.", Health: 8, UsedUp: true},
+			"bread", "loaf", "loaf of bread")
+		bread.SetValue(5)
+		// Three game days from fresh to mould
+		bread.SetDurability(mud.Durability{Max: 72, DecayPerHour: 1,
+			Conditions: []string{ "mouldy", "stale", "fresh" },
+			Message: "crumbles into mould"})
+		return bread
+	}
+}
+
+// NewRemedy makes something which has effect when the verb is used on it
+func NewRemedy(universe *mud.Universe, verb string, description string,
+	effect mud.Effect, handles... string) *simple.PhysicalObject {
+	remedy := simple.NewPhysicalObject(universe)
+	remedy.SetDescription(description)
+	remedy.SetVisible(true)
+	remedy.SetCarryable(true)
+	remedy.SetTextHandles(handles...)
+	remedy.SetEffect(verb, effect)
+	remedy.SetValue(20)
+	remedy.SetStackable(true)
+	return remedy
+}
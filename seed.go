@@ -52,32 +52,109 @@ Gold Street runs south. There is a Patrician Foods to the northeast.`)
 	universe.Add(goldSt1)
 	mud.ConnectNorthSouth(goldSt1, oldAve3)
 
-	puritan := NewPuritan(universe)
-	theBall := NewBall(universe, "&red;red&;")
-	theClock := NewClock(universe)
-	ff := NewFlipFlop(universe)
+	foyer := mud.NewRoom(universe, 0,`
+Gilroy Foyer
 
-	universe.Add(theClock)
+A marble foyer with a sweeping staircase. The butler eyes your
+purse. The exit is southwest.`)
+	universe.Add(foyer)
+	mud.ConnectWithConnCreator(mud.WithExitOptions(mud.NEtoSWRoomConnection,
+		mud.ExitOptions{Condition: "money 1000",
+			Refusal: "The butler bars your way: \"The Gilroys receive only persons of means.\""}))(gilroyEstate, foyer)
 
 	room := mud.NewRoom(universe, 0, "You are in a bedroom.")
-	room.AddChild(theBall)
-	room.AddChild(theClock)
-	room.AddChild(puritan)
-	room.AddChild(ff)
-	puritan.SetRoom(room)
+	for _, name := range([]string{"ball", "clock", "puritan", "flip-flop",
+		"brass key", "chest", "leather cap"}) {
+		placePrototype(universe, room, name)
+	}
 
 	room2 := mud.NewRoom(universe, 0, "You are in a bathroom.")
+	placePrototype(universe, room2, "lantern")
+	placePrototype(universe, room2, "bag")
+	placePrototype(universe, room2, "healing potion")
+	placePrototype(universe, room2, "bandage")
+	placePrototype(universe, room2, "loaf of bread")
 
 	tree := MakeFruitTree(universe, "peach")
 	room2.AddChild(tree)
 	tree.room = room2
 
-	mud.ConnectEastWest(room, room2)
+	bathroomDoor := mud.DoorRoomConnectCreator("east", "west",
+		"brass key", mud.DoorClosed)
+	mud.ConnectWithConnCreator(bathroomDoor)(room, room2)
+
+	cellar := mud.NewRoom(universe, 0,`
+Cellar
+
+A damp cellar beneath the bathroom. A chute leads up and out to the
+town square.`)
+	universe.Add(cellar)
+	cellar.SetProperty("light", "0")
+	mud.ConnectWithConnCreator(mud.WithExitOptions(mud.UpDownRoomConnection,
+		mud.ExitOptions{Hidden: true}))(cellar, room2)
+	mud.ConnectWithConnCreator(mud.WithExitOptions(
+		mud.SimpleRoomConnectCreator("chute", ""),
+		mud.ExitOptions{OneWay: true}))(cellar, townSquare)
+
+	belfry := mud.NewRoom(universe, 0,`
+Belfry
+
+A cramped belfry above the town square. A ladder leads down, and a
+shimmering portal hangs in the air beside the bell.`)
+	universe.Add(belfry)
+	var ladder mud.ExitOptions
+	ladder.Depart[mud.SideA] = "climbs the ladder"
+	ladder.Arrive[mud.SideA] = "climbs up from below"
+	ladder.Depart[mud.SideB] = "climbs down the ladder"
+	ladder.Arrive[mud.SideB] = "climbs down from the belfry"
+	mud.ConnectWithConnCreator(mud.WithExitOptions(
+		mud.SimpleRoomConnectCreator("climb ladder", "climb down"),
+		ladder))(townSquare, belfry)
+	mud.ConnectWithConnCreator(
+		mud.PortalRoomConnectCreator("portal"))(belfry, room)
+
+	parallax := mud.NewZone(universe, "Parallax")
+	parallax.SetLevelRange(1, 10)
+	for _, r := range []*mud.Room{townSquare, oldAve1, oldAve2,
+		oldAve3, goldSt1, belfry} {
+		r.SetZone(parallax)
+	}
+	gilroy := mud.NewZone(universe, "Gilroy Estate")
+	gilroy.SetLevelRange(5, 15)
+	gilroy.SetResetPolicy(mud.ResetAlways)
+	gilroyEstate.SetZone(gilroy)
+	foyer.SetZone(gilroy)
+	placePrototype(universe, foyer, "short sword")
+	placePrototype(universe, foyer, "belt of strength")
+
+	parallax.SetProperty("outdoors", "yes")
+	gilroy.SetProperty("outdoors", "yes")
+	belfry.SetProperty("outdoors", "no")
+	foyer.SetProperty("outdoors", "no")
+	townSquare.SetProperty("desc:night",
+		"Lamplight pools around the edges of the empty square.")
+	townSquare.SetProperty("light", "1")
+
+	townSquare.SetFlag(mud.FlagRecall, true)
+	townSquare.SetFlag(mud.FlagSafe, true)
+	room.SetFlag(mud.FlagPrivate, true)
+	room.SetResets([]mud.ResetRule{{Count: 1, Prototype: "ball"},
+		{Count: 1, Prototype: "puritan", WorldMax: 1}})
+	belfry.SetFlag(mud.FlagNoPioneer, true)
+
+	belfry.SetExtraDescription("bell",
+		"A great bronze bell, green with age. Someone has scratched " +
+		"\"G.G. was here\" into its lip.")
+	foyer.SetExtraDescription("staircase",
+		"The staircase sweeps up to a gallery of Gilroy portraits.")
+	foyer.SetExtraDescription("butler",
+		"The butler is impeccable, and quite unimpressed by you.")
 
 	return townSquare
 }
 
 func LoadUniverse(universe *mud.Universe) *mud.Room {
+	mud.LoadZones(universe)
 	roomIds := universe.Store.GlobalSetGet("rooms")
 	roomConnIds := universe.Store.GlobalSetGet("roomConnects")
 	for _, roomId := range(roomIds) {
@@ -98,4 +175,13 @@ func LoadUniverse(universe *mud.Universe) *mud.Room {
 		}
 	}
 	return universe.Rooms[1]
-}
\ No newline at end of file
+}
+
+// placePrototype puts a new object made by the named prototype in r
+func placePrototype(u *mud.Universe, r *mud.Room, name string) {
+	if o, ok := mud.MakePrototype(u, name); ok {
+		r.AddChild(o)
+	} else {
+		mud.Log("[warn] seed has no prototype", name)
+	}
+}
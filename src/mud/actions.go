@@ -13,6 +13,17 @@ func noSpaceMsg(name string) string {
 func noCarryMsg(name string) string {
 	return name + " cannot be carried.\n"
 }
+func tooHeavyMsg(name string) string {
+	return name + " is too heavy for you to lift.\n"
+}
+
+// cantCarryMsg says why p can't carry o, called name
+func cantCarryMsg(p *Player, o PhysicalObject, name string) string {
+	if p.CarriedWeight() + WeightOf(o) > p.MaxWeight() {
+		return tooHeavyMsg(name)
+	}
+	return noSpaceMsg(name)
+}
 
 func (p PlayerTakeAction) Targets() []PhysicalObject {
 	targets := make([]PhysicalObject, 1)
@@ -27,9 +38,9 @@ func (p PlayerTakeAction) Exec() {
 		stim := PlayerPickupStimulus{player: player, obj: target}
 		if target.Carryable() {
 			if player.TakeObject(&target, room) {
-				room.stimuliBroadcast <- stim
+				room.Broadcast(stim)
 			} else {
-				player.WriteString(noSpaceMsg(p.userTargetIdent))
+				player.WriteString(cantCarryMsg(player, target, p.userTargetIdent))
 			}
 		} else {
 			player.WriteString(noCarryMsg(p.userTargetIdent))
@@ -44,6 +55,7 @@ type PlayerDropAction struct {
 	player *Player
 	target PhysicalObject
 	userTargetIdent string
+	count int
 }
 
 func (p PlayerDropAction) Targets() []PhysicalObject {
@@ -55,11 +67,20 @@ func (p PlayerDropAction) Source() PhysicalObject { return p.player }
 func (p PlayerDropAction) Exec() {
 	player := p.player
 	room := player.room
+	if message, forbidden := room.Forbids("drop"); forbidden {
+		player.WriteString(message)
+		return
+	}
 	if target, ok := player.PerceiveList(InvContext)[p.userTargetIdent]; ok {
+		target, msg := SplitStack(target, p.count)
+		if msg != "" {
+			player.WriteString(msg)
+			return
+		}
 		stim := PlayerDropStimulus{player: player, obj: target}
 		
 		if player.DropObject(&target, room) {
-			room.stimuliBroadcast <- stim
+			room.Broadcast(stim)
 		} else {
 			player.WriteString("Object cannot be dropped.\n")
 		}
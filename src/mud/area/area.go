@@ -0,0 +1,455 @@
+// area reads Diku, Merc and ROM style .are area files.
+package area
+
+import ("bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings")
+
+// Exit directions, in the order of the D0-D5 exit numbers
+var Directions = []string{ "north", "east", "south", "west", "up", "down" }
+
+// Room flags, as bits (ROM letters A, D, ...)
+const (
+	RoomDark = 1 << 0
+	RoomNoMob = 1 << 2
+	RoomIndoors = 1 << 3
+	RoomPrivate = 1 << 9
+	RoomSafe = 1 << 10
+	RoomSolitary = 1 << 11
+	RoomNoRecall = 1 << 13
+)
+
+// Sector 0 is inside; the rest (city, field, forest...) are outside
+const SectorInside = 0
+
+// Object wear flag for things which can be picked up
+const WearTake = 1 << 0
+
+type ExtraDesc struct {
+	Keywords []string
+	Text string
+}
+
+type Exit struct {
+	Direction int
+	Description string
+	Keywords string
+	Locks int
+	Key int
+	ToRoom int
+}
+
+type Room struct {
+	Vnum int
+	Name string
+	Description string
+	Flags uint64
+	Sector int
+	Exits []Exit
+	Extras []ExtraDesc
+}
+
+type Object struct {
+	Vnum int
+	Keywords []string
+	Short string
+	Long string
+	ItemType string
+	WearFlags uint64
+	Weight int
+	Cost int
+	Extras []ExtraDesc
+}
+
+type Mobile struct {
+	Vnum int
+	Keywords []string
+	Short string
+	Long string
+	Description string
+}
+
+// Reset is one line of #RESETS, e.g. M 0 3000 1 3001 is Command "M"
+// with Args [0 3000 1 3001].
+type Reset struct {
+	Command string
+	Args []int
+	Line int
+}
+
+/*
+ Area is the content of an area file. Warnings lists what was skipped
+ as unsupported, with line numbers.
+ */
+type Area struct {
+	Filename string
+	Name string
+	Credits string
+	LowVnum, HighVnum int
+	Rooms []*Room
+	Objects []*Object
+	Mobiles []*Mobile
+	Resets []Reset
+	Warnings []string
+}
+
+type parser struct {
+	lines []string
+	pos int
+	area *Area
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) warnf(format string, args ...interface{}) {
+	p.area.Warnings = append(p.area.Warnings,
+		fmt.Sprintf("line %d: %s", p.pos, fmt.Sprintf(format, args...)))
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.lines) }
+
+func (p *parser) next() (string, error) {
+	if p.done() {
+		return "", p.errorf("unexpected end of file")
+	}
+	p.pos++
+	return p.lines[p.pos-1], nil
+}
+
+// peek is the next line which isn't blank, without reading it
+func (p *parser) peek() string {
+	for !p.done() && strings.TrimSpace(p.lines[p.pos]) == "" {
+		p.pos++
+	}
+	if p.done() {
+		return ""
+	}
+	return strings.TrimSpace(p.lines[p.pos])
+}
+
+// tilde reads a string ending with ~, which may run over many lines
+func (p *parser) tilde() (string, error) {
+	text := []string{}
+	for {
+		line, err := p.next()
+		if err != nil {
+			return "", err
+		}
+		if i := strings.Index(line, "~"); i >= 0 {
+			text = append(text, line[:i])
+			return strings.TrimRight(strings.Join(text, "\n"), "\n "), nil
+		}
+		text = append(text, line)
+	}
+}
+
+func (p *parser) vnum() (int, bool, error) {
+	line := p.peek()
+	p.pos++
+	if !strings.HasPrefix(line, "#") {
+		return 0, false, p.errorf("expected #vnum, found '%s'", line)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return 0, false, p.errorf("bad vnum '%s'", line)
+	}
+	return n, n != 0, nil
+}
+
+// skipToHash skips lines up to the next one starting with #
+func (p *parser) skipToHash() {
+	for p.peek() != "" && !strings.HasPrefix(p.peek(), "#") {
+		p.pos++
+	}
+}
+
+/*
+ ParseFlags reads flags written as a number ("1032"), sums of numbers
+ ("8|1024") or ROM letters ("DK", where A is bit 0 and a is bit 26).
+ */
+func ParseFlags(s string) (uint64, error) {
+	var flags uint64
+	for _, part := range(strings.Split(s, "|")) {
+		if n, err := strconv.ParseUint(part, 10, 64); err == nil {
+			flags |= n
+			continue
+		}
+		for _, c := range(part) {
+			switch {
+			case c >= 'A' && c <= 'Z':
+				flags |= 1 << uint(c - 'A')
+			case c >= 'a' && c <= 'z':
+				flags |= 1 << uint(c - 'a' + 26)
+			default:
+				return 0, fmt.Errorf("bad flags '%s'", s)
+			}
+		}
+	}
+	return flags, nil
+}
+
+func (p *parser) extraDesc() (ExtraDesc, error) {
+	keywords, err := p.tilde()
+	if err != nil {
+		return ExtraDesc{}, err
+	}
+	text, err := p.tilde()
+	return ExtraDesc{Keywords: strings.Fields(keywords), Text: text}, err
+}
+
+func (p *parser) parseArea(header string) error {
+	a := p.area
+	if rest := strings.TrimSpace(strings.TrimPrefix(header, "#AREA")); rest != "" {
+		// Old style: #AREA {levels} Author Name~
+		a.Name = strings.TrimSpace(strings.TrimSuffix(rest, "~"))
+		return nil
+	}
+	var err error
+	if a.Filename, err = p.tilde(); err != nil { return err }
+	if a.Name, err = p.tilde(); err != nil { return err }
+	if a.Credits, err = p.tilde(); err != nil { return err }
+	if fields := strings.Fields(p.peek()); len(fields) == 2 {
+		a.LowVnum, _ = strconv.Atoi(fields[0])
+		a.HighVnum, _ = strconv.Atoi(fields[1])
+		p.pos++
+	}
+	return nil
+}
+
+func (p *parser) parseMobiles() error {
+	for {
+		vnum, more, err := p.vnum()
+		if err != nil || !more {
+			return err
+		}
+		m := &Mobile{Vnum: vnum}
+		var keywords string
+		if keywords, err = p.tilde(); err != nil { return err }
+		m.Keywords = strings.Fields(keywords)
+		if m.Short, err = p.tilde(); err != nil { return err }
+		if m.Long, err = p.tilde(); err != nil { return err }
+		if m.Description, err = p.tilde(); err != nil { return err }
+		// Stats, race and so on aren't used
+		p.skipToHash()
+		p.area.Mobiles = append(p.area.Mobiles, m)
+	}
+}
+
+/*
+ weightAndCost reads an object's stats line: "weight cost rent" in
+ Diku files, or "level weight cost condition" with the weight in
+ tenths of a pound in ROM ones.
+ */
+func weightAndCost(line string) (int, int) {
+	fields := strings.Fields(line)
+	if len(fields) >= 4 {
+		weight, _ := strconv.Atoi(fields[1])
+		cost, _ := strconv.Atoi(fields[2])
+		return weight / 10, cost
+	}
+	if len(fields) == 3 {
+		weight, _ := strconv.Atoi(fields[0])
+		cost, _ := strconv.Atoi(fields[1])
+		return weight, cost
+	}
+	return 0, 0
+}
+
+func (p *parser) parseObjects() error {
+	for {
+		vnum, more, err := p.vnum()
+		if err != nil || !more {
+			return err
+		}
+		o := &Object{Vnum: vnum}
+		var keywords string
+		if keywords, err = p.tilde(); err != nil { return err }
+		o.Keywords = strings.Fields(keywords)
+		if o.Short, err = p.tilde(); err != nil { return err }
+		if o.Long, err = p.tilde(); err != nil { return err }
+		// Material (ROM) or action description (Diku)
+		if _, err = p.tilde(); err != nil { return err }
+		typeLine, err := p.next()
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(typeLine)
+		if len(fields) < 3 {
+			return p.errorf("expected type, extra and wear flags")
+		}
+		o.ItemType = fields[0]
+		if o.WearFlags, err = ParseFlags(fields[2]); err != nil {
+			return p.errorf("%s", err)
+		}
+		for n := 0; p.peek() != "" && !strings.HasPrefix(p.peek(), "#"); n++ {
+			line, _ := p.next()
+			switch {
+			case strings.TrimSpace(line) == "E":
+				extra, err := p.extraDesc()
+				if err != nil {
+					return err
+				}
+				o.Extras = append(o.Extras, extra)
+			case n == 1:
+				o.Weight, o.Cost = weightAndCost(line)
+			}
+			// Values and affects aren't used
+		}
+		p.area.Objects = append(p.area.Objects, o)
+	}
+}
+
+func (p *parser) parseRooms() error {
+	for {
+		vnum, more, err := p.vnum()
+		if err != nil || !more {
+			return err
+		}
+		r := &Room{Vnum: vnum}
+		if r.Name, err = p.tilde(); err != nil { return err }
+		if r.Description, err = p.tilde(); err != nil { return err }
+		flagLine, err := p.next()
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(flagLine)
+		if len(fields) < 3 {
+			return p.errorf("expected area, room flags and sector")
+		}
+		if r.Flags, err = ParseFlags(fields[1]); err != nil {
+			return p.errorf("%s", err)
+		}
+		r.Sector, _ = strconv.Atoi(fields[2])
+		if err := p.parseRoomParts(r); err != nil {
+			return err
+		}
+		p.area.Rooms = append(p.area.Rooms, r)
+	}
+}
+
+func (p *parser) parseRoomParts(r *Room) error {
+	for {
+		line, err := p.next()
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "S":
+			return nil
+		case line == "":
+		case line == "E":
+			extra, err := p.extraDesc()
+			if err != nil {
+				return err
+			}
+			r.Extras = append(r.Extras, extra)
+		case len(line) == 2 && line[0] == 'D' && line[1] >= '0' && line[1] <= '5':
+			e := Exit{Direction: int(line[1] - '0')}
+			if e.Description, err = p.tilde(); err != nil { return err }
+			if e.Keywords, err = p.tilde(); err != nil { return err }
+			doorLine, err := p.next()
+			if err != nil {
+				return err
+			}
+			fields := strings.Fields(doorLine)
+			if len(fields) < 3 {
+				return p.errorf("expected locks, key and destination")
+			}
+			e.Locks, _ = strconv.Atoi(fields[0])
+			e.Key, _ = strconv.Atoi(fields[1])
+			e.ToRoom, _ = strconv.Atoi(fields[2])
+			r.Exits = append(r.Exits, e)
+		case strings.HasPrefix(line, "C ") || strings.HasPrefix(line, "O "):
+			p.warnf("room %d: clan and owner aren't supported", r.Vnum)
+			if !strings.Contains(line, "~") {
+				if _, err := p.tilde(); err != nil { return err }
+			}
+		default:
+			p.warnf("room %d: '%s' isn't supported", r.Vnum, line)
+		}
+	}
+}
+
+func (p *parser) parseResets() error {
+	for {
+		line, err := p.next()
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0 || fields[0] == "*":
+			continue
+		case fields[0] == "S":
+			return nil
+		}
+		r := Reset{Command: fields[0], Line: p.pos}
+		// Anything after the numbers is a comment
+		for _, field := range(fields[1:]) {
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				break
+			}
+			r.Args = append(r.Args, n)
+		}
+		p.area.Resets = append(p.area.Resets, r)
+	}
+}
+
+// Parse reads an area file. Errors give the line they were found on.
+func Parse(r io.Reader) (*Area, error) {
+	p := &parser{area: new(Area)}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.lines = append(p.lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for {
+		header := p.peek()
+		if header == "" || header == "#$" {
+			return p.area, nil
+		}
+		p.pos++
+		var err error
+		switch strings.Fields(header)[0] {
+		case "#AREA":
+			err = p.parseArea(header)
+		case "#MOBILES":
+			err = p.parseMobiles()
+		case "#OBJECTS":
+			err = p.parseObjects()
+		case "#ROOMS":
+			err = p.parseRooms()
+		case "#RESETS":
+			err = p.parseResets()
+		default:
+			if !strings.HasPrefix(header, "#") {
+				return nil, p.errorf("expected a section, found '%s'", header)
+			}
+			p.warnf("section %s isn't supported", header)
+			p.skipSection()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// skipSection skips to the next section header, such as #ROOMS
+func (p *parser) skipSection() {
+	for line := p.peek(); line != ""; line = p.peek() {
+		if len(line) > 1 && line[0] == '#' &&
+			(line[1] == '$' || (line[1] >= 'A' && line[1] <= 'Z')) {
+			return
+		}
+		p.pos++
+	}
+}
@@ -0,0 +1,132 @@
+package area
+
+import ("strings"
+	"testing")
+
+const sampleArea = `#AREA
+midgaard.are~
+Midgaard~
+{ 1 50} Diku    Midgaard~
+3000 3099
+
+#MOBILES
+#3000
+wizard~
+the wizard~
+A wizard walks around behind the counter, talking to himself.
+~
+The wizard looks old and senile.
+~
+human~
+ABV DFJ 900 0
+10 0 2d10+100 100d10+0 1d8+0 punch
+#0
+
+#OBJECTS
+#3001
+barrel beer~
+a barrel~
+A beer barrel has been left here.~
+wood~
+drink_container 0 A
+300 300 0 ' ' 0
+20 100 0 P
+E
+barrel~
+It says 'Midgaard Brewery'.
+~
+#0
+
+#ROOMS
+#3001
+The Temple Of Midgaard~
+You are in the southern end of the temple hall.
+~
+0 AK 0
+D0
+~
+door~
+1 3100 3002
+E
+altar~
+A huge altar.
+~
+S
+#3002
+The Temple Hall~
+The hall is lit by torches.
+~
+0 0 1
+D2
+~
+door~
+1 3100 3001
+S
+#0
+
+#RESETS
+* The wizard stands guard
+M 0 3000 1 3001	* the wizard
+D 0 3001 0 2
+S
+
+#SHOPS
+3000 2 3 4 10 0 0 0 0 105 0 23
+0
+
+#$
+`
+
+func TestParse(t *testing.T) {
+	a, err := Parse(strings.NewReader(sampleArea))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Name != "Midgaard" || a.LowVnum != 3000 || a.HighVnum != 3099 {
+		t.Errorf("area header %q %d-%d", a.Name, a.LowVnum, a.HighVnum)
+	}
+	if len(a.Mobiles) != 1 || a.Mobiles[0].Short != "the wizard" {
+		t.Fatalf("mobiles %+v", a.Mobiles)
+	}
+	if len(a.Objects) != 1 || a.Objects[0].WearFlags != WearTake ||
+		len(a.Objects[0].Extras) != 1 || a.Objects[0].Weight != 10 {
+		t.Fatalf("objects %+v", a.Objects)
+	}
+	if len(a.Rooms) != 2 {
+		t.Fatalf("%d rooms", len(a.Rooms))
+	}
+	temple := a.Rooms[0]
+	if temple.Flags != RoomDark|RoomSafe {
+		t.Errorf("temple flags %b", temple.Flags)
+	}
+	if len(temple.Exits) != 1 || temple.Exits[0].ToRoom != 3002 ||
+		temple.Exits[0].Key != 3100 || temple.Exits[0].Locks != 1 {
+		t.Errorf("temple exits %+v", temple.Exits)
+	}
+	if len(temple.Extras) != 1 || temple.Extras[0].Keywords[0] != "altar" {
+		t.Errorf("temple extras %+v", temple.Extras)
+	}
+	if len(a.Resets) != 2 || a.Resets[0].Command != "M" ||
+		len(a.Resets[0].Args) != 4 || a.Resets[0].Args[3] != 3001 {
+		t.Errorf("resets %+v", a.Resets)
+	}
+	if len(a.Warnings) != 1 || !strings.Contains(a.Warnings[0], "#SHOPS") {
+		t.Errorf("warnings %v", a.Warnings)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	_, err := Parse(strings.NewReader("#ROOMS\n#3001\nNo tilde\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("expected an error on line 3, got %v", err)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	for s, want := range(map[string]uint64{"0": 0, "1032": 1032,
+		"8|1024": 1032, "DK": 1032, "a": 1 << 26}) {
+		if got, err := ParseFlags(s); err != nil || got != want {
+			t.Errorf("ParseFlags(%q) = %d, %v", s, got, err)
+		}
+	}
+}
@@ -6,7 +6,11 @@ type CommandSource interface {
 	Commands() map[string]Command
 }
 
-var GlobalCommands map[string]Command
+var GlobalCommands = make(map[string]Command)
+
+// SocialCommands are tried after the room's CommandSources, so that
+// a social never hides a command something in the room gives.
+var SocialCommands = make(map[string]Command)
 
 func givesCommands(o interface{}, ifTrue func(CommandSource)) {
 	oAsCmdSrc, isCmdSrc := o.(CommandSource)
@@ -15,8 +19,6 @@ func givesCommands(o interface{}, ifTrue func(CommandSource)) {
 }
 
 func init() {
-	GlobalCommands = make(map[string]Command)
-
 	containerHelper := new(FlexObjHandlerPair)
 	containerHelper.Add = func(fc *FlexContainer, o interface{}) {
 		givesCommands(o, func(CommandSource) {
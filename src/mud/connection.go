@@ -8,14 +8,21 @@ import ("net"
  to a connection state and handles the I/O.
  */
 type UserConnection struct {
-	// buffered channel which emits user input
+	// buffered channel which emits user input to the ConnectionState
 	FromUser chan string
+	// buffered channel which emits user input once in band, for the
+	// Player's ReadLoop
+	InBand chan string
 	// buffered channel which sends its input to user
 	ToUser chan string
 	// handler for disconnect
 	OnDisconnect func()
 	// current ConnectionState
 	State ConnectionState
+	// states to return to as pushed states finish
+	pushedStates []ConnectionState
+	// states waiting for readLoop to push them
+	pushes chan ConnectionState
 	// arbitrary data to attach to UserConnection
 	Data map[string]interface{}
 	socket net.Conn
@@ -77,8 +84,10 @@ func NewUserConnection(socket net.Conn, connectState ConnectionState) *UserConne
 	c.socket = socket
 	c.State = connectState
 	c.FromUser = make(chan string, 10)
+	c.InBand = make(chan string, 10)
 	c.ToUser = make(chan string, 10)
 	c.done = make(chan bool, 1)
+	c.pushes = make(chan ConnectionState, 1)
 	c.outOfBand = true
 	c.Data = make(map[string]interface{})
 	
@@ -87,6 +96,30 @@ func NewUserConnection(socket net.Conn, connectState ConnectionState) *UserConne
 }
 
 
+/*
+ PushState takes the connection out of band into state, e.g. an editor,
+ until state's Respond returns false; then the previous state is
+ restored and input goes back to the Player. The switch is made by
+ readLoop, which owns the connection's state.
+ */
+func (c *UserConnection) PushState(state ConnectionState) {
+	c.pushes <- state
+}
+
+func (c *UserConnection) pushState(state ConnectionState) {
+	c.pushedStates = append(c.pushedStates, c.State)
+	c.State = state
+	state.Init(c)
+	c.outOfBand = true
+}
+
+func (c *UserConnection) popState() {
+	if n := len(c.pushedStates); n > 0 {
+		c.State = c.pushedStates[n-1]
+		c.pushedStates = c.pushedStates[:n-1]
+	}
+}
+
 func (c *UserConnection) Close() { 
 	c.done <- true
 }
@@ -100,8 +133,11 @@ func (c *UserConnection) Write(text string) {
 }
 
 func (c *UserConnection) readLoop() {
-	rawBuf := make([]byte, 1024)
+	lines := make(chan string)
+	stop := make(chan bool)
 	defer c.socket.Close()
+	defer close(stop)
+	go c.readSocket(lines, stop)
 
 	c.State.Init(c)
 	for {
@@ -109,17 +145,37 @@ func (c *UserConnection) readLoop() {
 		case <-c.done:
 			c.OnDisconnect()
 			return
-		default:
-			n, err := c.socket.Read(rawBuf)
-			if err == nil {
-				strCommand := string(rawBuf[:n])
-				c.FromUser <- strings.TrimRight(strCommand,"\n\r")
-			} else {
-				c.done <- true
+		case state := <-c.pushes:
+			c.pushState(state)
+		case strCommand, ok := <-lines:
+			if !ok {
+				c.OnDisconnect()
+				return
 			}
 			if(c.outOfBand) {
+				c.FromUser <- strCommand
 				c.outOfBand = c.State.Respond(c)
+				if !c.outOfBand { c.popState() }
+			} else {
+				c.InBand <- strCommand
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+
+// readSocket sends each line read to lines, closing it on error
+func (c *UserConnection) readSocket(lines chan string, stop chan bool) {
+	rawBuf := make([]byte, 1024)
+	for {
+		n, err := c.socket.Read(rawBuf)
+		if err != nil {
+			close(lines)
+			return
+		}
+		select {
+		case lines <- strings.TrimRight(string(rawBuf[:n]),"\n\r"):
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package mud
+
+import ("fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync")
+
+func init() {
+	GlobalCommands["eat"] = consumeCommand("eat")
+	GlobalCommands["drink"] = consumeCommand("drink")
+	GlobalCommands["use"] = consumeCommand("use")
+
+	PlayerPerceptions["consume"] = doesPerceiveConsume
+}
+
+const MaxHealth = 100
+
+/*
+ Buff changes a stat for some game minutes, like "well fed" (strength
+ +1) or "sick" (strength -3). A player has one buff of each name.
+ */
+type Buff struct {
+	Name string
+	Stat string
+	Amount int
+	Minutes int
+	expires GameTime
+}
+
+/*
+ Effect is what eating, drinking or using something does: a Message
+ for the player (after "You eat..."), Health restored (or lost), Buffs,
+ and whether the object is UsedUp. Stimulus, if set, is broadcast to
+ the room after the player is seen to consume the object, e.g. to
+ describe its taste.
+ */
+type Effect struct {
+	Message string
+	Health int
+	Buffs []Buff
+	UsedUp bool
+	Stimulus Stimulus
+}
+
+/*
+ Consumable is implemented by objects which can be eaten, drunk or
+ used. Consume is asked with the verb, and returns false if the
+ object can't be used that way.
+ */
+type Consumable interface {
+	PhysicalObject
+	Consume(p *Player, verb string) (Effect, bool)
+}
+
+/*
+ effects guards every player's health and buffs. Effects are applied
+ in the player's room, while the clock expires buffs and the universe
+ reads them to save. It isn't kept in Player, as players are copied
+ by value.
+ */
+var effects sync.RWMutex
+
+func (p *Player) Health() int {
+	effects.RLock()
+	defer effects.RUnlock()
+	return p.health
+}
+
+// AdjustHealth changes p's health, keeping it between 0 and MaxHealth
+func (p *Player) AdjustHealth(amount int) {
+	effects.Lock()
+	defer effects.Unlock()
+	p.health += amount
+	if p.health > MaxHealth {
+		p.health = MaxHealth
+	}
+	if p.health < 0 {
+		p.health = 0
+	}
+}
+
+func (p *Player) now() GameTime {
+	if p.Universe.Clock == nil {
+		return 0
+	}
+	return p.Universe.Clock.Now()
+}
+
+// AddBuff gives p a buff, replacing any other of the same name
+func (p *Player) AddBuff(b Buff) {
+	b.expires = p.now() + GameTime(b.Minutes)
+	effects.Lock()
+	defer effects.Unlock()
+	p.removeBuff(b.Name)
+	p.buffs = append(p.buffs, b)
+}
+
+// removeBuff takes away p's buff called name, with effects locked
+func (p *Player) removeBuff(name string) {
+	kept := []Buff{}
+	for _, b := range(p.buffs) {
+		if b.Name != name { kept = append(kept, b) }
+	}
+	p.buffs = kept
+}
+
+// Buffs returns a copy of p's buffs
+func (p *Player) Buffs() []Buff {
+	effects.RLock()
+	defer effects.RUnlock()
+	return append([]Buff{}, p.buffs...)
+}
+
+func (p *Player) buffModifier(stat string) int {
+	effects.RLock()
+	defer effects.RUnlock()
+	total := 0
+	for _, b := range(p.buffs) {
+		if b.Stat == stat { total += b.Amount }
+	}
+	return total
+}
+
+// ExpireBuffs ends p's buffs which have run out by now, telling p
+func (p *Player) ExpireBuffs(now GameTime) {
+	expired := []Buff{}
+	effects.Lock()
+	for _, b := range(p.buffs) {
+		if b.expires <= now {
+			p.removeBuff(b.Name)
+			expired = append(expired, b)
+		}
+	}
+	effects.Unlock()
+	for _, b := range(expired) {
+		p.WriteString("You are no longer " + b.Name + ".\n")
+	}
+	if len(expired) > 0 {
+		p.Save()
+	}
+}
+
+// StartEffects has buffs run out as game time passes
+func StartEffects(u *Universe) {
+	u.Clock.OnMinute(func(now GameTime) {
+		for _, p := range(u.Players) { p.ExpireBuffs(now) }
+	})
+}
+
+// buffStrings persists buffs as "name=stat amount expires"
+func buffStrings(buffs []Buff) []string {
+	props := make(map[string]string)
+	for _, b := range(buffs) {
+		props[b.Name] = fmt.Sprintf("%s %d %d", b.Stat, b.Amount, b.expires)
+	}
+	return propertyStrings(props)
+}
+
+func parseBuffStrings(strs []string) []Buff {
+	buffs := []Buff{}
+	for name, text := range(parsePropertyStrings(strs)) {
+		fields := strings.Fields(text)
+		if len(fields) != 3 {
+			continue
+		}
+		b := Buff{Name: name, Stat: fields[0]}
+		b.Amount, _ = strconv.Atoi(fields[1])
+		expires, _ := strconv.Atoi(fields[2])
+		b.expires = GameTime(expires)
+		buffs = append(buffs, b)
+	}
+	sort.Slice(buffs, func(i, j int) bool { return buffs[i].Name < buffs[j].Name })
+	return buffs
+}
+
+// Apply gives p the effect's health and buffs
+func (e Effect) Apply(p *Player) {
+	p.AdjustHealth(e.Health)
+	for _, b := range(e.Buffs) { p.AddBuff(b) }
+}
+
+type ConsumeStimulus struct {
+	Stimulus
+	player *Player
+	obj PhysicalObject
+	verb string
+	message string
+}
+
+func (s ConsumeStimulus) StimType() string { return "consume" }
+func (s ConsumeStimulus) Description(p Perceiver) string {
+	if playerReceiver, ok := p.(*Player); ok && s.player.id == playerReceiver.id {
+		text := "You " + s.verb + " " + strings.ToLower(s.obj.Description()) + ".\n"
+		if s.message != "" {
+			text += s.message + "\n"
+		}
+		return text
+	}
+	return s.player.name + " " + s.verb + "s " +
+		strings.ToLower(s.obj.Description()) + ".\n"
+}
+
+func doesPerceiveConsume(p Player, s Stimulus) bool { return true }
+
+type ConsumeAction struct {
+	InterObjectAction
+	player *Player
+	verb string
+	what string
+}
+
+func (a ConsumeAction) Targets() []PhysicalObject { return []PhysicalObject{} }
+func (a ConsumeAction) Source() PhysicalObject { return a.player }
+func (a ConsumeAction) Exec() {
+	player := a.player
+	// What is eaten must be carried; fountains and levers needn't be
+	context := LookContext
+	if a.verb == "eat" {
+		context = InvContext
+	}
+	o, ok := player.PerceiveList(context)[a.what]
+	if !ok {
+		player.WriteString("You have no " + a.what + ".\n")
+		return
+	}
+	c, ok := o.(Consumable)
+	var effect Effect
+	if ok {
+		effect, ok = c.Consume(player, a.verb)
+	}
+	if !ok {
+		player.WriteString("You can't " + a.verb + " that.\n")
+		return
+	}
+	player.room.Broadcast(ConsumeStimulus{player: player, obj: o, verb: a.verb,
+		message: effect.Message})
+	if effect.Stimulus != nil {
+		player.room.Broadcast(effect.Stimulus)
+	}
+	effect.Apply(player)
+	player.Save()
+	// Only one of a stack is used up
+	if s, ok := o.(Stackable); ok && effect.UsedUp && CountOf(o) > 1 {
+		s.SetCount(s.Count() - 1)
+	} else if effect.UsedUp {
+		player.inventory.Remove(o)
+		Destroy(player.Universe, o)
+	} else if vanish, gone := Wear(o, player, player.room); gone {
+		// Already running the room's actions
+		vanish.Exec()
+	}
+}
+
+func consumeCommand(verb string) Command {
+	return func(p *Player, args []string) {
+		if len(args) == 0 {
+			p.WriteString(strings.Title(verb) + " usage: " + verb + " [object].\n")
+			return
+		}
+		p.room.Act(ConsumeAction{player: p, verb: verb,
+			what: strings.ToLower(strings.Join(args, " "))})
+	}
+}
@@ -0,0 +1,73 @@
+package mud
+
+import ("reflect"
+	"testing")
+
+func TestBuffs(t *testing.T) {
+	p := &Player{strength: DefaultStrength, health: 95, Universe: new(Universe)}
+	p.AddBuff(Buff{Name: "sick", Stat: "strength", Amount: -3, Minutes: 120})
+	p.AddBuff(Buff{Name: "well fed", Stat: "strength", Amount: 1, Minutes: 60})
+	p.AddBuff(Buff{Name: "sick", Stat: "strength", Amount: -2, Minutes: 30})
+	if len(p.Buffs()) != 2 || p.Strength() != 9 {
+		t.Errorf("expected two buffs and strength 9, got %v and %d",
+			p.Buffs(), p.Strength())
+	}
+	saved := buffStrings(p.Buffs())
+	if want := []string{"sick=strength -2 30", "well fed=strength 1 60"};
+		!reflect.DeepEqual(saved, want) {
+		t.Errorf("buffs saved as %v, want %v", saved, want)
+	}
+	if loaded := buffStrings(parseBuffStrings(saved)); !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("buffs came back as %v", loaded)
+	}
+	p.ExpireBuffs(29)
+	if len(p.Buffs()) != 2 {
+		t.Error("no buff should have run out yet")
+	}
+
+	Effect{Health: 10}.Apply(p)
+	if p.Health() != MaxHealth {
+		t.Errorf("health should stop at %d, got %d", MaxHealth, p.Health())
+	}
+	p.AdjustHealth(-200)
+	if p.Health() != 0 {
+		t.Errorf("health should stop at 0, got %d", p.Health())
+	}
+}
+
+func TestHealthAndBuffsSaved(t *testing.T) {
+	u := testUniverse()
+	p := NewPlayer(u, "Bob")
+	Effect{Health: -30, Buffs: []Buff{{Name: "sick", Stat: "strength",
+		Amount: -3, Minutes: 120}}}.Apply(p)
+	p.Save()
+
+	loaded := LoadPlayer(u, "Bob")
+	if loaded.Health() != MaxHealth - 30 {
+		t.Errorf("reloaded health %d, want %d", loaded.Health(), MaxHealth - 30)
+	}
+	saved, want := buffStrings(loaded.Buffs()), buffStrings(p.Buffs())
+	if !reflect.DeepEqual(saved, want) || loaded.Strength() != 7 {
+		t.Errorf("reloaded buffs %v, want %v", saved, want)
+	}
+}
+
+// Run with -race: the clock expires buffs while the room applies them
+func TestExpireBuffsWhileApplying(t *testing.T) {
+	p := NewPlayer(testUniverse(), "Carol")
+	p.Conn = &UserConnection{socket: &testSocket{}}
+	done := make(chan bool)
+	go func() {
+		for now := 0; now < 100; now++ { p.ExpireBuffs(GameTime(now)) }
+		done <- true
+	}()
+	for i := 0; i < 100; i++ {
+		Effect{Health: -1, Buffs: []Buff{{Name: "sick", Stat: "strength",
+			Amount: -1}}}.Apply(p)
+		p.Strength()
+	}
+	<- done
+	if p.Health() != MaxHealth - 100 {
+		t.Errorf("health should be %d, got %d", MaxHealth - 100, p.Health())
+	}
+}
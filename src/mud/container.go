@@ -0,0 +1,286 @@
+package mud
+
+import "strings"
+
+func init() {
+	GlobalCommands["put"] = put
+
+	PlayerPerceptions["container"] = doesPerceiveContainer
+}
+
+/*
+ Container is a PhysicalObject which holds others, like a chest or a
+ bag. It has a lid which opens and closes like a door (its
+ ContainerState), and may lock with a key answering to KeyHandle.
+ Capacity counts nested contents, as Load does.
+ */
+type Container interface {
+	PhysicalObject
+	Contents() []PhysicalObject
+	AddContent(o PhysicalObject)
+	RemoveContent(o PhysicalObject)
+	Capacity() int
+	ContainerState() DoorState
+	SetContainerState(state DoorState)
+	KeyHandle() string
+}
+
+/*
+ Load is how much room o takes up in what holds it: its bulk, plus the
+ load of everything inside it.
+ */
+func Load(o PhysicalObject) int {
+	load := BulkOf(o)
+	if c, ok := o.(Container); ok {
+		load += ContentsLoad(c)
+	}
+	return load
+}
+
+func ContentsLoad(c Container) int {
+	load := 0
+	for _, o := range(c.Contents()) { load += Load(o) }
+	return load
+}
+
+// Holds is true if o is in c, or in something in c
+func Holds(c Container, o PhysicalObject) bool {
+	for _, inside := range(c.Contents()) {
+		if inside == o {
+			return true
+		}
+		if nested, ok := inside.(Container); ok && Holds(nested, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanPut returns why o can't go in c, or "" if it can
+func CanPut(c Container, o PhysicalObject) string {
+	switch {
+	case PhysicalObject(c) == o:
+		return "You can't put something inside itself.\n"
+	case c.ContainerState() != DoorOpen:
+		return "It is " + c.ContainerState().String() + ".\n"
+	}
+	if inner, ok := o.(Container); ok && Holds(inner, c) {
+		return "You can't put something inside itself.\n"
+	}
+	if ContentsLoad(c) + Load(o) > c.Capacity() {
+		return "There isn't room.\n"
+	}
+	return ""
+}
+
+// Load is the bulk p carries, counting the contents of containers
+func (p *Player) Load() int {
+	load := 0
+	for _, o := range(p.Inventory()) { load += Load(o) }
+	return load
+}
+
+// CanCarry is true if p has room for o and what it holds, and can lift them
+func (p *Player) CanCarry(o PhysicalObject) bool {
+	return p.Load() + Load(o) <= p.MaxLoad() &&
+		p.CarriedWeight() + WeightOf(o) <= p.MaxWeight()
+}
+
+// findContainer finds a container p can see or carries
+func findContainer(p *Player, name string) (Container, string) {
+	o, ok := p.PerceiveList(LookContext)[name]
+	if !ok {
+		return nil, "You see no " + name + " here.\n"
+	}
+	c, ok := o.(Container)
+	if !ok {
+		return nil, "That isn't a container.\n"
+	}
+	return c, ""
+}
+
+func findContent(c Container, name string) (PhysicalObject, bool) {
+	for _, o := range(c.Contents()) {
+		for _, handle := range(o.TextHandles()) {
+			if handle == name { return o, true }
+		}
+	}
+	return nil, false
+}
+
+// splitOn splits args around the first word sep, e.g. "ball in bag"
+func splitOn(args []string, sep string) (string, string, bool) {
+	for i, arg := range(args) {
+		if arg == sep && i > 0 && i < len(args) - 1 {
+			return strings.ToLower(strings.Join(args[:i], " ")),
+				strings.ToLower(strings.Join(args[i+1:], " ")), true
+		}
+	}
+	return "", "", false
+}
+
+type ContainerStimulus struct {
+	Stimulus
+	player *Player
+	container Container
+	obj PhysicalObject
+	verb string
+}
+
+var containerThirdPerson = map[string]string{
+	"put": "puts", "take": "takes",
+	"open": "opens", "close": "closes", "lock": "locks", "unlock": "unlocks",
+}
+
+func (s ContainerStimulus) StimType() string { return "container" }
+func (s ContainerStimulus) Description(p Perceiver) string {
+	who, verb := s.player.name, containerThirdPerson[s.verb]
+	if playerReceiver, ok := p.(*Player); ok && s.player.id == playerReceiver.id {
+		who, verb = "You", s.verb
+	}
+	container := strings.ToLower(s.container.Description())
+	switch s.verb {
+	case "put":
+		return who + " " + verb + " " + strings.ToLower(s.obj.Description()) +
+			" in " + container + ".\n"
+	case "take":
+		return who + " " + verb + " " + strings.ToLower(s.obj.Description()) +
+			" from " + container + ".\n"
+	}
+	return who + " " + verb + " " + container + ".\n"
+}
+
+func doesPerceiveContainer(p Player, s Stimulus) bool { return true }
+
+type PutAction struct {
+	InterObjectAction
+	player *Player
+	what string
+	into string
+}
+
+func (a PutAction) Targets() []PhysicalObject { return []PhysicalObject{} }
+func (a PutAction) Source() PhysicalObject { return a.player }
+func (a PutAction) Exec() {
+	player := a.player
+	o, ok := player.PerceiveList(InvContext)[a.what]
+	if !ok {
+		player.WriteString(a.what + " not in your inventory.\n")
+		return
+	}
+	c, msg := findContainer(player, a.into)
+	if msg == "" {
+		msg = CanPut(c, o)
+	}
+	if msg != "" {
+		player.WriteString(msg)
+		return
+	}
+	player.inventory.Remove(o)
+	if !MergeStack(player.Universe, c.Contents(), o) {
+		c.AddContent(o)
+	}
+	player.room.Broadcast(ContainerStimulus{player: player, container: c,
+		obj: o, verb: "put"})
+}
+
+type TakeFromAction struct {
+	InterObjectAction
+	player *Player
+	what string
+	from string
+}
+
+func (a TakeFromAction) Targets() []PhysicalObject { return []PhysicalObject{} }
+func (a TakeFromAction) Source() PhysicalObject { return a.player }
+func (a TakeFromAction) Exec() {
+	player := a.player
+	c, msg := findContainer(player, a.from)
+	if msg != "" {
+		player.WriteString(msg)
+		return
+	}
+	if c.ContainerState() != DoorOpen {
+		player.WriteString("It is " + c.ContainerState().String() + ".\n")
+		return
+	}
+	o, ok := findContent(c, a.what)
+	switch {
+	case !ok:
+		player.WriteString("There is no " + a.what + " in it.\n")
+	case !o.Carryable():
+		player.WriteString(noCarryMsg(a.what))
+	case !carrying(player, c) && !player.CanCarry(o):
+		player.WriteString(cantCarryMsg(player, o, a.what))
+	default:
+		c.RemoveContent(o)
+		player.Add(o)
+		player.room.Broadcast(ContainerStimulus{player: player, container: c,
+			obj: o, verb: "take"})
+	}
+}
+
+// carrying is true if c is in p's inventory, at any depth
+func carrying(p *Player, c Container) bool {
+	for _, o := range(p.Inventory()) {
+		if o == PhysicalObject(c) {
+			return true
+		}
+		if held, ok := o.(Container); ok && Holds(held, c) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ containerLock opens, closes, locks or unlocks a container p can see,
+ returning false if there is none by that name.
+ */
+func containerLock(p *Player, name string, verb string) bool {
+	o, ok := p.PerceiveList(LookContext)[strings.ToLower(name)]
+	if !ok {
+		return false
+	}
+	c, ok := o.(Container)
+	if !ok {
+		return false
+	}
+	state, msg := ChangeLock(c.ContainerState(), verb,
+		func() bool { return p.CarriesKey(c.KeyHandle()) })
+	if msg != "" {
+		p.WriteString(msg)
+		return true
+	}
+	c.SetContainerState(state)
+	p.room.Broadcast(ContainerStimulus{player: p, container: c, verb: verb})
+	return true
+}
+
+// LookIn lists what is in a container
+func LookIn(p *Player, name string) {
+	c, msg := findContainer(p, strings.ToLower(name))
+	switch {
+	case msg != "":
+		p.WriteString(msg)
+	case c.ContainerState() != DoorOpen:
+		p.WriteString("It is " + c.ContainerState().String() + ".\n")
+	case len(c.Contents()) == 0:
+		p.WriteString("It is empty.\n")
+	default:
+		text := c.Description() + " holds:\n"
+		for _, o := range(c.Contents()) {
+			text += "  " + StackDescription(o) + "\n"
+		}
+		p.WriteString(text)
+	}
+}
+
+func put(p *Player, args []string) {
+	what, into, ok := splitOn(args, "in")
+	if !ok {
+		p.WriteString("Put usage: put [object] in [container].\n")
+		return
+	}
+	p.room.Act(PutAction{player: p, what: what, into: into})
+}
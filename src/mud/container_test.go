@@ -0,0 +1,148 @@
+package mud
+
+import ("net"
+	"strings"
+	"testing")
+
+type testObject struct {
+	PhysicalObject
+	name string
+}
+
+func (o *testObject) Description() string { return o.name }
+
+type testContainer struct {
+	testObject
+	contents []PhysicalObject
+	capacity int
+	state DoorState
+}
+
+func (c *testContainer) Contents() []PhysicalObject { return c.contents }
+func (c *testContainer) AddContent(o PhysicalObject) { c.contents = append(c.contents, o) }
+func (c *testContainer) RemoveContent(o PhysicalObject) {
+	for i, inside := range(c.contents) {
+		if inside == o {
+			c.contents = append(c.contents[:i], c.contents[i+1:]...)
+			return
+		}
+	}
+}
+func (c *testContainer) Capacity() int { return c.capacity }
+func (c *testContainer) ContainerState() DoorState { return c.state }
+func (c *testContainer) SetContainerState(s DoorState) { c.state = s }
+func (c *testContainer) KeyHandle() string { return "" }
+
+func TestContainerLoad(t *testing.T) {
+	chest := &testContainer{capacity: 3}
+	bag := &testContainer{capacity: 2}
+	ball, key := &testObject{name: "ball"}, &testObject{name: "key"}
+	bag.AddContent(ball)
+	chest.AddContent(bag)
+
+	if Load(chest) != 3 || ContentsLoad(chest) != 2 {
+		t.Errorf("chest should load 3 with 2 inside, got %d and %d",
+			Load(chest), ContentsLoad(chest))
+	}
+	if !Holds(chest, ball) || Holds(bag, key) {
+		t.Error("Holds should look through nested containers")
+	}
+	if msg := CanPut(chest, key); msg != "" {
+		t.Errorf("key should fit in chest: %q", msg)
+	}
+	chest.AddContent(key)
+	if CanPut(chest, &testObject{name: "apple"}) == "" {
+		t.Error("chest should be full")
+	}
+	if CanPut(bag, chest) == "" || CanPut(bag, bag) == "" {
+		t.Error("a container shouldn't go inside itself")
+	}
+	bag.state = DoorClosed
+	if CanPut(bag, key) != "It is closed.\n" {
+		t.Errorf("closed bag gave %q", CanPut(bag, key))
+	}
+}
+
+// testThing can be seen, named and carried, unlike testObject
+type testThing struct {
+	testObject
+	room *Room
+	handle string
+	carryable bool
+	weight int
+}
+
+func (o *testThing) SetRoom(r *Room) { o.room = r }
+func (o *testThing) Room() *Room { return o.room }
+func (o *testThing) Visible() bool { return true }
+func (o *testThing) TextHandles() []string { return []string{o.handle} }
+func (o *testThing) Carryable() bool { return o.carryable }
+func (o *testThing) Weight() int { return o.weight }
+func (o *testThing) Bulk() int { return 1 }
+func (o *testThing) Value() Currency { return 0 }
+
+type testChest struct {
+	testThing
+	testContainer
+}
+
+func (c *testChest) Description() string { return c.testThing.Description() }
+
+// testSocket keeps what is written to a player
+type testSocket struct {
+	net.Conn
+	written string
+}
+
+func (s *testSocket) Write(b []byte) (int, error) {
+	s.written += string(b)
+	return len(b), nil
+}
+
+func testPlayer(r *Room) (*Player, *testSocket) {
+	p := NewPlayer(r.universe, "Alice")
+	socket := &testSocket{}
+	p.Conn = &UserConnection{socket: socket}
+	p.room = r
+	return p, socket
+}
+
+func TestPutAndTakeFrom(t *testing.T) {
+	r := NewRoom(testUniverse(), 0, "A cellar.")
+	chest := &testChest{testThing{handle: "chest"}, testContainer{capacity: 5}}
+	r.AddChild(chest)
+	p, socket := testPlayer(r)
+	ball := &testThing{handle: "ball", carryable: true, weight: 1}
+	p.Add(ball)
+	told := func(want string) {
+		if !strings.Contains(socket.written, want) {
+			t.Errorf("expected to be told %q, got %q", want, socket.written)
+		}
+		socket.written = ""
+	}
+
+	chest.state = DoorClosed
+	PutAction{player: p, what: "ball", into: "chest"}.Exec()
+	told("It is closed.\n")
+	chest.state = DoorOpen
+	PutAction{player: p, what: "ball", into: "chest"}.Exec()
+	if !Holds(chest, ball) || len(p.Inventory()) != 0 {
+		t.Error("the ball should be in the chest")
+	}
+
+	chest.state = DoorLocked
+	TakeFromAction{player: p, what: "ball", from: "chest"}.Exec()
+	told("It is locked.\n")
+	chest.state = DoorOpen
+	chest.AddContent(&testThing{handle: "anvil", weight: 5})
+	TakeFromAction{player: p, what: "anvil", from: "chest"}.Exec()
+	told(noCarryMsg("anvil"))
+	chest.AddContent(&testThing{handle: "boulder", carryable: true, weight: 1000})
+	TakeFromAction{player: p, what: "boulder", from: "chest"}.Exec()
+	told(tooHeavyMsg("boulder"))
+
+	TakeFromAction{player: p, what: "ball", from: "chest"}.Exec()
+	if Holds(chest, ball) || len(p.Inventory()) != 1 {
+		t.Error("the ball should be taken from the chest")
+	}
+}
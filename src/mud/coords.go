@@ -0,0 +1,230 @@
+package mud
+
+import ("fmt"
+	"strconv"
+	"strings")
+
+func init() {
+	GlobalCommands["map"] = showMap
+}
+
+// Coord is a room's position; north is +Y, east is +X and up is +Z.
+type Coord struct {
+	X, Y, Z int
+}
+
+func (c Coord) Add(o Coord) Coord {
+	return Coord{c.X + o.X, c.Y + o.Y, c.Z + o.Z}
+}
+
+func (c Coord) String() string {
+	return fmt.Sprintf("%d,%d,%d", c.X, c.Y, c.Z)
+}
+
+func ParseCoord(s string) (Coord, error) {
+	var c Coord
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return c, fmt.Errorf("coordinate '%s' should be x,y,z", s)
+	}
+	var err [3]error
+	c.X, err[0] = strconv.Atoi(parts[0])
+	c.Y, err[1] = strconv.Atoi(parts[1])
+	c.Z, err[2] = strconv.Atoi(parts[2])
+	for _, e := range(err) {
+		if e != nil {
+			return c, fmt.Errorf("coordinate '%s' should be x,y,z", s)
+		}
+	}
+	return c, nil
+}
+
+var compassOffsets = map[string]Coord{
+	"north": {0, 1, 0}, "south": {0, -1, 0},
+	"east": {1, 0, 0}, "west": {-1, 0, 0},
+	"northeast": {1, 1, 0}, "southwest": {-1, -1, 0},
+	"northwest": {-1, 1, 0}, "southeast": {1, -1, 0},
+	"up": {0, 0, 1}, "down": {0, 0, -1},
+}
+
+// CompassOffset is the change in position when travelling through
+// an exit with a compass name. ok is false for other exit names.
+func CompassOffset(exitName string) (offset Coord, ok bool) {
+	offset, ok = compassOffsets[exitName]
+	return
+}
+
+func (r *Room) Coords() (Coord, bool) {
+	if r.coords == nil {
+		return Coord{}, false
+	}
+	return *r.coords, true
+}
+
+func (r *Room) SetCoords(c Coord) { r.coords = &c }
+
+// RoomAt finds the room at c, or nil if there is none
+func RoomAt(u *Universe, c Coord) *Room {
+	for _, r := range(u.Rooms) {
+		if r.coords != nil && *r.coords == c {
+			return r
+		}
+	}
+	return nil
+}
+
+/*
+ CoordConflict records a compass exit whose rooms' coordinates don't
+ agree with its direction, e.g. two rooms joined east/west which are
+ not side by side.
+ */
+type CoordConflict struct {
+	From *Room
+	Exit string
+	Expected Coord
+	Actual Coord
+}
+
+func (c CoordConflict) String() string {
+	to := ""
+	c.From.WithExit(c.Exit, func(rei *RoomExitInfo) {
+		to = strconv.Itoa(rei.OtherSide().id)
+	}, func() {})
+	return fmt.Sprintf("room %d exit %s leads to room %s at %s, expected %s",
+		c.From.id, c.Exit, to, c.Actual, c.Expected)
+}
+
+// placeAcross gives an uncoordinated room on one side of a compass
+// exit coordinates relative to the other side.
+func placeAcross(exit *RoomExitInfo) *CoordConflict {
+	offset, isCompass := CompassOffset(exit.Name())
+	if !isCompass {
+		return nil
+	}
+	from, to := exit.exit.RoomA(), exit.OtherSide()
+	if exit.exitSide == SideB {
+		from = exit.exit.RoomB()
+	}
+	fromCoord, fromOk := from.Coords()
+	if !fromOk {
+		return nil
+	}
+	expected := fromCoord.Add(offset)
+	if toCoord, toOk := to.Coords(); !toOk {
+		to.SetCoords(expected)
+	} else if toCoord != expected {
+		return &CoordConflict{From: from, Exit: exit.Name(),
+			Expected: expected, Actual: toCoord}
+	}
+	return nil
+}
+
+/*
+ InferCoordinates walks compass exits outward from start, which is
+ placed at the origin if it has no coordinates, and positions every
+ reachable room which has none. Exits which disagree with existing
+ coordinates are returned as conflicts.
+ */
+func InferCoordinates(start *Room) []CoordConflict {
+	conflicts := []CoordConflict{}
+	if _, ok := start.Coords(); !ok {
+		start.SetCoords(Coord{})
+	}
+	seen := map[*Room]bool{start: true}
+	queue := []*Room{start}
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+		for i := range(r.exits) {
+			exit := &r.exits[i]
+			if conflict := placeAcross(exit); conflict != nil {
+				conflicts = append(conflicts, *conflict)
+			}
+			next := exit.OtherSide()
+			if _, placed := next.Coords(); placed && !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return conflicts
+}
+
+/*
+ RenderMap draws an ASCII map of the rooms within radius steps of
+ center on the same level, reachable through exits visible to viewer.
+ The center room is drawn as [*].
+ */
+func RenderMap(center *Room, viewer *Player, radius int) string {
+	size := 2*radius + 1
+	rows := make([][]byte, 2*size - 1)
+	for i := range(rows) {
+		rows[i] = []byte(strings.Repeat(" ", 4*size - 1))
+	}
+	put := func(c Coord, dRow int, dCol int, s string) {
+		row := 2*(radius - c.Y) + dRow
+		col := 4*(c.X + radius) + dCol
+		if row >= 0 && row < len(rows) && col >= 0 && col+len(s) <= len(rows[row]) {
+			copy(rows[row][col:], s)
+		}
+	}
+
+	positions := map[*Room]Coord{center: {}}
+	occupied := map[Coord]bool{{}: true}
+	queue := []*Room{center}
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+		here := positions[r]
+		for i := range(r.exits) {
+			exit := &r.exits[i]
+			offset, isCompass := CompassOffset(exit.Name())
+			if !isCompass || offset.Z != 0 || !exit.VisibleTo(viewer) {
+				continue
+			}
+			there := here.Add(offset)
+			if there.X < -radius || there.X > radius ||
+				there.Y < -radius || there.Y > radius {
+				continue
+			}
+			// Links sit between cells: 2 columns right of the
+			// room's center for east, 2 left for west, and so on.
+			switch {
+			case offset.Y == 0:
+				put(here, 0, 1 + 2*offset.X, "-")
+			case offset.X == 0:
+				put(here, -offset.Y, 1, "|")
+			case offset.X == offset.Y:
+				put(here, -offset.Y, 1 + 2*offset.X, "/")
+			default:
+				put(here, -offset.Y, 1 + 2*offset.X, "\\")
+			}
+			next := exit.OtherSide()
+			if _, seen := positions[next]; !seen && !occupied[there] {
+				positions[next] = there
+				occupied[there] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	for r, c := range(positions) {
+		if r == center {
+			put(c, 0, 0, "[*]")
+		} else {
+			put(c, 0, 0, "[ ]")
+		}
+	}
+
+	lines := []string{}
+	for _, row := range(rows) {
+		lines = append(lines, strings.TrimRight(string(row), " "))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+func showMap(p *Player, args []string) {
+	p.WriteString(RenderMap(p.room, p, 2))
+	if c, ok := p.room.Coords(); ok {
+		p.WriteString("You are at " + c.String() + ".\n")
+	}
+}
@@ -0,0 +1,55 @@
+package mud
+
+import "testing"
+
+// Rooms and connections without a Universe, so nothing is persisted
+func testRoom(id int) *Room {
+	return &Room{id: id, exits: []RoomExitInfo{}}
+}
+
+func testConnect(a *Room, b *Room, exitGen RoomConnCreator) {
+	rc := exitGen()
+	rc.SetRooms(a, b)
+	a.exits = append(a.exits, RoomExitInfo{exitSide: SideA, exit: rc})
+	b.exits = append(b.exits, RoomExitInfo{exitSide: SideB, exit: rc})
+}
+
+func TestInferCoordinates(t *testing.T) {
+	center, east, north := testRoom(1), testRoom(2), testRoom(3)
+	testConnect(center, east, EastWestRoomConnection)
+	testConnect(north, center, NorthSouthRoomConnection)
+
+	conflicts := InferCoordinates(center)
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %v", conflicts)
+	}
+	if c, _ := east.Coords(); c != (Coord{1, 0, 0}) {
+		t.Errorf("east room should be at 1,0,0, is at %s", c)
+	}
+	// north is side A of a north/south connection, so center is
+	// north of it
+	if c, _ := north.Coords(); c != (Coord{0, -1, 0}) {
+		t.Errorf("north room should be at 0,-1,0, is at %s", c)
+	}
+
+	east.SetCoords(Coord{5, 5, 0})
+	if conflicts := InferCoordinates(center); len(conflicts) == 0 {
+		t.Errorf("misplaced room should be reported as a conflict")
+	}
+}
+
+func TestRenderMap(t *testing.T) {
+	center, east, north, south := testRoom(1), testRoom(2), testRoom(3), testRoom(4)
+	testConnect(center, east, EastWestRoomConnection)
+	testConnect(center, north, SimpleRoomConnectCreator("north", "south"))
+	testConnect(center, south, WithExitOptions(
+		SimpleRoomConnectCreator("south", "north"),
+		ExitOptions{Hidden: true}))
+
+	expected := "    [ ]\n" +
+		"     |\n" +
+		"    [*]-[ ]\n"
+	if got := RenderMap(center, nil, 1); got != expected {
+		t.Errorf("unexpected map:\n%s\nexpected:\n%s", got, expected)
+	}
+}
@@ -0,0 +1,231 @@
+package mud
+
+import ("strconv"
+	"strings")
+
+func init() {
+	PersistentKeys["roomConnect:door"] = []string{ "doorState", "keyHandle" }
+	RoomConnKinds["door"] = loadDoorConnCreator
+
+	GlobalCommands["open"] = doorCommand("open")
+	GlobalCommands["close"] = doorCommand("close")
+	GlobalCommands["lock"] = doorCommand("lock")
+	GlobalCommands["unlock"] = doorCommand("unlock")
+
+	PlayerPerceptions["door"] = doesPerceiveDoor
+}
+
+type DoorState int
+
+const (
+	DoorOpen DoorState = iota
+	DoorClosed
+	DoorLocked
+)
+
+func (d DoorState) String() string {
+	switch d {
+	case DoorClosed:
+		return "closed"
+	case DoorLocked:
+		return "locked"
+	}
+	return "open"
+}
+
+/*
+ DoorRoomConnection is a RoomConnection with a door between the
+ rooms. The door can be opened and closed, and locked or unlocked by
+ a player carrying an object which answers to keyHandle.
+ */
+type DoorRoomConnection struct {
+	SimpleRoomConnection
+	state DoorState
+	keyHandle string
+}
+
+func (d DoorRoomConnection) DoorState() DoorState { return d.state }
+func (d DoorRoomConnection) KeyHandle() string { return d.keyHandle }
+func (d DoorRoomConnection) State() string { return d.state.String() }
+
+func (d DoorRoomConnection) CanPass(p *Player, from RoomSide) (bool, string) {
+	if d.state != DoorOpen {
+		return false, "The door is " + d.state.String() + ".\n"
+	}
+	return d.SimpleRoomConnection.CanPass(p, from)
+}
+
+// HasKey is true if p is carrying the key to the door
+func (d DoorRoomConnection) HasKey(p *Player) bool {
+	return p.CarriesKey(d.keyHandle)
+}
+
+// CarriesKey is true if p carries something answering to keyHandle
+func (p *Player) CarriesKey(keyHandle string) bool {
+	if keyHandle == "" {
+		return false
+	}
+	_, ok := p.PerceiveList(InvContext)[keyHandle]
+	return ok
+}
+
+func (d DoorRoomConnection) PersistentValues() map[string]interface{} {
+	vals := d.SimpleRoomConnection.PersistentValues()
+	vals["kind"] = "door"
+	vals["doorState"] = strconv.Itoa(int(d.state))
+	vals["keyHandle"] = d.keyHandle
+	return vals
+}
+
+func (d *DoorRoomConnection) Save() string {
+	return d.saveValues(d.PersistentValues())
+}
+
+/*
+ ChangeLock works out the state a door or lid is left in by the verb
+ (open, close, lock, unlock). hasKey is only asked when a key is
+ needed. It returns a message explaining why the change could not be
+ made, or "" on success.
+ */
+func ChangeLock(state DoorState, verb string, hasKey func() bool) (DoorState, string) {
+	switch verb {
+	case "open":
+		switch state {
+		case DoorLocked:
+			return state, "It is locked.\n"
+		case DoorOpen:
+			return state, "It is already open.\n"
+		}
+		return DoorOpen, ""
+	case "close":
+		if state != DoorOpen {
+			return state, "It is already closed.\n"
+		}
+		return DoorClosed, ""
+	case "lock":
+		switch {
+		case state == DoorOpen:
+			return state, "You must close it first.\n"
+		case state == DoorLocked:
+			return state, "It is already locked.\n"
+		case !hasKey():
+			return state, "You don't have the key.\n"
+		}
+		return DoorLocked, ""
+	case "unlock":
+		switch {
+		case state != DoorLocked:
+			return state, "It isn't locked.\n"
+		case !hasKey():
+			return state, "You don't have the key.\n"
+		}
+		return DoorClosed, ""
+	}
+	return state, ""
+}
+
+// apply changes the door's state as the verb requests (see ChangeLock)
+func (d *DoorRoomConnection) apply(p *Player, verb string) string {
+	state, msg := ChangeLock(d.state, verb, func() bool { return d.HasKey(p) })
+	d.state = state
+	return msg
+}
+
+func DoorRoomConnectCreator(a string, b string, keyHandle string, state DoorState) RoomConnCreator {
+	return func() RoomConnection {
+		door := new(DoorRoomConnection)
+		door.aExitName, door.bExitName = a, b
+		door.keyHandle = keyHandle
+		door.state = state
+		return door
+	}
+}
+
+func loadDoorConnCreator(universe *Universe, vals Pvals) RoomConnCreator {
+	aExitName,_ := vals["aExitName"].(string)
+	bExitName,_ := vals["bExitName"].(string)
+	keyHandle,_ := vals["keyHandle"].(string)
+	stateStr,_ := vals["doorState"].(string)
+	state, _ := strconv.Atoi(stateStr)
+	return DoorRoomConnectCreator(aExitName, bExitName,
+		keyHandle, DoorState(state))
+}
+
+type DoorAction struct {
+	InterObjectAction
+	player *Player
+	exitName string
+	verb string
+}
+
+func (d DoorAction) Targets() []PhysicalObject { return []PhysicalObject{} }
+func (d DoorAction) Source() PhysicalObject { return d.player }
+func (d DoorAction) Exec() {
+	player := d.player
+	room := player.room
+	if containerLock(player, d.exitName, d.verb) {
+		return
+	}
+	room.WithVisibleExit(player, d.exitName, func(rei *RoomExitInfo) {
+		door, ok := rei.exit.(*DoorRoomConnection)
+		if !ok {
+			player.WriteString("There is no door " + d.exitName + ".\n")
+			return
+		}
+		if msg := door.apply(player, d.verb); msg != "" {
+			player.WriteString(msg)
+			return
+		}
+		room.Broadcast(DoorStimulus{player: player,
+			exitName: rei.Name(), verb: d.verb})
+		rei.OtherSide().Broadcast(DoorStimulus{player: player,
+			exitName: rei.ReverseName(), verb: d.verb, farSide: true})
+	}, func() {
+		player.WriteString("No visible exit " + d.exitName + ".\n")
+	})
+}
+
+func doorCommand(verb string) Command {
+	return func(p *Player, args []string) {
+		if len(args) < 1 {
+			p.WriteString(strings.Title(verb) + " usage: " +
+				verb + " [exit or container]. Ex. " + verb + " east\n")
+			return
+		}
+		p.room.Act(DoorAction{player: p,
+			exitName: strings.Join(args, " "), verb: verb})
+	}
+}
+
+type DoorStimulus struct {
+	Stimulus
+	player *Player
+	exitName string
+	verb string
+	farSide bool
+}
+
+var doorPastTense = map[string]string{
+	"open": "opened", "close": "closed",
+	"lock": "locked", "unlock": "unlocked",
+}
+var doorThirdPerson = map[string]string{
+	"open": "opens", "close": "closes",
+	"lock": "locks", "unlock": "unlocks",
+}
+
+func (s DoorStimulus) StimType() string { return "door" }
+func (s DoorStimulus) Description(p Perceiver) string {
+	if s.farSide {
+		return "The " + s.exitName + " door is " +
+			doorPastTense[s.verb] + " from the other side.\n"
+	}
+	playerReceiver, ok := p.(*Player)
+	if ok && s.player.id == playerReceiver.id {
+		return "You " + s.verb + " the " + s.exitName + " door.\n"
+	}
+	return s.player.name + " " + doorThirdPerson[s.verb] +
+		" the " + s.exitName + " door.\n"
+}
+
+func doesPerceiveDoor(p Player, s Stimulus) bool { return true }
@@ -0,0 +1,56 @@
+package mud
+
+import "testing"
+
+func TestLockAndUnlock(t *testing.T) {
+	u := testUniverse()
+	hall, study := NewRoom(u, 0, "A hall."), NewRoom(u, 0, "A study.")
+	rc := ConnectWithConnCreator(
+		DoorRoomConnectCreator("north", "south", "brass key", DoorClosed))(hall, study)
+	door := rc.(*DoorRoomConnection)
+	p, socket := testPlayer(hall)
+
+	DoorAction{player: p, exitName: "north", verb: "lock"}.Exec()
+	if door.DoorState() != DoorClosed || socket.written != "You don't have the key.\n" {
+		t.Errorf("locking without the key should fail, got %s and %q",
+			door.DoorState(), socket.written)
+	}
+	p.Add(&testThing{handle: "brass key", carryable: true})
+	DoorAction{player: p, exitName: "north", verb: "lock"}.Exec()
+	if door.DoorState() != DoorLocked {
+		t.Errorf("the key should lock the door, got %s", door.DoorState())
+	}
+	DoorAction{player: p, exitName: "north", verb: "unlock"}.Exec()
+	if door.DoorState() != DoorClosed {
+		t.Errorf("the key should unlock the door, got %s", door.DoorState())
+	}
+	for _, o := range(p.Inventory()) { p.inventory.Remove(o) }
+	door.state = DoorLocked
+	socket.written = ""
+	DoorAction{player: p, exitName: "north", verb: "unlock"}.Exec()
+	if door.DoorState() != DoorLocked || socket.written != "You don't have the key.\n" {
+		t.Errorf("unlocking without the key should fail, got %s and %q",
+			door.DoorState(), socket.written)
+	}
+}
+
+func TestClosedDoorBlocksPassage(t *testing.T) {
+	u := testUniverse()
+	hall, study := quietRoom(u), quietRoom(u)
+	rc := ConnectWithConnCreator(
+		DoorRoomConnectCreator("north", "south", "", DoorClosed))(hall, study)
+	p, socket := testPlayer(hall)
+
+	goExit(p, []string{"north"})
+	if p.room != hall || socket.written != "The door is closed.\n" {
+		t.Errorf("a closed door should stop the player, got %q", socket.written)
+	}
+	rc.(*DoorRoomConnection).state = DoorOpen
+	goExit(p, []string{"north"})
+	if p.room != study {
+		t.Error("an open door should let the player through")
+	}
+	if ok, _ := rc.CanPass(p, SideB); !ok {
+		t.Error("an open door should be passable from both sides")
+	}
+}
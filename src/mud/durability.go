@@ -0,0 +1,146 @@
+package mud
+
+func init() {
+	PlayerPerceptions["vanish"] = doesPerceiveVanish
+}
+
+/*
+ Durability is a component for objects which wear out with use, like
+ tools, or decay with time, like food and corpses. Condition counts
+ down from Max; at zero the object falls apart, and is seen to go with
+ Message (e.g. "rots away"). Conditions names the condition, worst
+ first, each for an equal share of Max; DefaultConditions if empty.
+ */
+type Durability struct {
+	Condition int
+	Max int
+	WearPerUse int
+	DecayPerHour int
+	Conditions []string
+	Message string
+}
+
+var DefaultConditions = []string{ "falling apart", "battered", "worn",
+	"good", "excellent" }
+
+/*
+ Durable is implemented by objects with a Durability. Objects which
+ might have one but don't (e.g. a simple.PhysicalObject) return nil.
+ */
+type Durable interface {
+	PhysicalObject
+	Durability() *Durability
+}
+
+func durabilityOf(o PhysicalObject) (*Durability, bool) {
+	if durable, ok := o.(Durable); ok && durable.Durability() != nil {
+		return durable.Durability(), true
+	}
+	return nil, false
+}
+
+// Damage takes n from the condition, returning true once it is worn out
+func (d *Durability) Damage(n int) bool {
+	d.Condition -= n
+	if d.Condition < 0 {
+		d.Condition = 0
+	}
+	return d.Condition == 0
+}
+
+func (d *Durability) ConditionName() string {
+	names := d.Conditions
+	if len(names) == 0 {
+		names = DefaultConditions
+	}
+	if d.Condition >= d.Max {
+		return names[len(names) - 1]
+	}
+	return names[d.Condition * len(names) / (d.Max + 1)]
+}
+
+// ConditionOf names o's condition, or "" if it doesn't have one
+func ConditionOf(o PhysicalObject) string {
+	if d, ok := durabilityOf(o); ok {
+		return d.ConditionName()
+	}
+	return ""
+}
+
+/*
+ Wear wears o, held by holder (a player or container) in r, down by a
+ use. If that wears it out, it returns the VanishAction to remove it,
+ for the caller to queue or, if already running r's actions, Exec.
+ */
+func Wear(o PhysicalObject, holder interface{}, r *Room) (VanishAction, bool) {
+	d, ok := durabilityOf(o)
+	if !ok || d.WearPerUse == 0 || !d.Damage(d.WearPerUse) {
+		return VanishAction{}, false
+	}
+	return VanishAction{Target: o, Holder: holder, Message: d.Message, room: r}, true
+}
+
+/*
+ StartDecay has durable objects decay as game hours pass. A room whose
+ queue is full skips the hour, rather than holding up the clock.
+ */
+func StartDecay(u *Universe) {
+	u.Clock.OnHour(func(now GameTime) {
+		for _, r := range(u.Rooms) {
+			select {
+			case r.interactionQueue <- DecayAction{room: r}:
+			default:
+				Log("[warn] room", r.id, "too busy to decay")
+			}
+		}
+	})
+}
+
+/*
+ DecayAction decays everything in a room by an hour: what lies there,
+ what its players carry and wear, and what is in containers among
+ them. What rots away vanishes at once.
+ */
+type DecayAction struct {
+	InterObjectAction
+	room *Room
+}
+
+func (a DecayAction) Targets() []PhysicalObject { return []PhysicalObject{} }
+func (a DecayAction) Source() PhysicalObject { return nil }
+func (a DecayAction) Exec() {
+	gone := decay(a.room.PhysicalObjects(), nil, a.room)
+	for _, p := range(a.room.players) {
+		gone = append(gone, decay(p.Inventory(), p, a.room)...)
+		gone = append(gone, decay(p.Equipped(), p, a.room)...)
+	}
+	for _, vanish := range(gone) { vanish.Exec() }
+}
+
+func decay(objs []PhysicalObject, holder interface{}, r *Room) []VanishAction {
+	gone := []VanishAction{}
+	for _, o := range(objs) {
+		if c, ok := o.(Container); ok {
+			gone = append(gone, decay(c.Contents(), c, r)...)
+		}
+		d, ok := durabilityOf(o)
+		if ok && d.DecayPerHour > 0 && d.Damage(d.DecayPerHour) {
+			gone = append(gone, VanishAction{Target: o, Holder: holder,
+				Message: d.Message, room: r})
+		}
+	}
+	return gone
+}
+
+type VanishStimulus struct {
+	Stimulus
+	obj PhysicalObject
+	message string
+}
+
+func (s VanishStimulus) StimType() string { return "vanish" }
+func (s VanishStimulus) Description(p Perceiver) string {
+	return StackDescription(s.obj) + " " + s.message + ".\n"
+}
+
+func doesPerceiveVanish(p Player, s Stimulus) bool { return true }
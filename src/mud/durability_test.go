@@ -0,0 +1,106 @@
+package mud
+
+import ("testing"
+	"time")
+
+type testDurable struct {
+	testObject
+	durability *Durability
+}
+
+func (o *testDurable) Durability() *Durability { return o.durability }
+
+func TestConditionNames(t *testing.T) {
+	d := &Durability{Condition: 72, Max: 72,
+		Conditions: []string{ "mouldy", "stale", "fresh" }}
+	for _, c := range([]struct{ condition int; name string }{
+		{72, "fresh"}, {49, "fresh"}, {48, "stale"}, {24, "mouldy"}, {0, "mouldy"},
+	}) {
+		d.Condition = c.condition
+		if name := d.ConditionName(); name != c.name {
+			t.Errorf("condition %d named %s, want %s", c.condition, name, c.name)
+		}
+	}
+	tool := &Durability{Condition: 40, Max: 40}
+	if tool.ConditionName() != "excellent" {
+		t.Errorf("new tool is %s", tool.ConditionName())
+	}
+}
+
+func TestDecay(t *testing.T) {
+	bread := &testDurable{testObject{name: "bread"},
+		&Durability{Condition: 2, Max: 2, DecayPerHour: 1, Message: "rots"}}
+	sword := &testDurable{testObject{name: "sword"},
+		&Durability{Condition: 1, Max: 1, WearPerUse: 1}}
+	bag := &testContainer{contents: []PhysicalObject{bread}}
+	objs := []PhysicalObject{bag, sword}
+
+	if gone := decay(objs, nil, nil); len(gone) != 0 || bread.durability.Condition != 1 {
+		t.Error("bread should be going off, not gone")
+	}
+	gone := decay(objs, nil, nil)
+	if len(gone) != 1 || gone[0].Target != bread || gone[0].Holder != bag {
+		t.Errorf("bread should rot away from the bag, got %v", gone)
+	}
+	if sword.durability.Condition != 1 {
+		t.Error("the sword shouldn't decay with time")
+	}
+	if _, worn := Wear(sword, nil, nil); !worn {
+		t.Error("the sword should wear out with one use")
+	}
+}
+
+/*
+ quietRoom is a room without loops, so its broadcasts can be counted,
+ and players can move through it without racing them.
+ */
+func quietRoom(u *Universe) *Room {
+	r := &Room{universe: u, stimuliBroadcast: make(chan Stimulus, 10),
+		players: make(map[int]*Player), exits: []RoomExitInfo{},
+		flags: make(map[string]bool), properties: make(map[string]string),
+		extraDescs: make(map[string]string),
+		children: NewFlexContainer("PhysicalObjects", "Persistents",
+			"RoomPhysicalObjects", "Perceivers", "CommandSources")}
+	r.children.Meta["Room"] = r
+	return r
+}
+
+func TestVanishFromCarrierRoom(t *testing.T) {
+	u := testUniverse()
+	pickedUp, here := quietRoom(u), quietRoom(u)
+	p, _ := testPlayer(here)
+	bread := &testThing{testObject: testObject{name: "bread"}, handle: "bread"}
+	bread.SetRoom(pickedUp)
+	p.Add(bread)
+
+	VanishAction{Target: bread, Holder: p, Message: "rots away"}.Exec()
+	if len(p.Inventory()) != 0 {
+		t.Error("the bread should be gone from the inventory")
+	}
+	if len(here.stimuliBroadcast) != 1 || len(pickedUp.stimuliBroadcast) != 0 {
+		t.Errorf("the bread should be seen to go where its carrier is, " +
+			"not where it was picked up")
+	}
+}
+
+func TestDecaySkipsBusyRooms(t *testing.T) {
+	u := testUniverse()
+	u.Clock = newGameClock(u, 1)
+	busy := quietRoom(u)
+	busy.id = 1
+	busy.interactionQueue = make(chan InterObjectAction, 1)
+	busy.interactionQueue <- DecayAction{room: busy}
+	u.Rooms[busy.id] = busy
+	StartDecay(u)
+
+	hourPassed := make(chan bool)
+	go func() {
+		u.Clock.Advance(MinutesPerHour)
+		hourPassed <- true
+	}()
+	select {
+	case <- hourPassed:
+	case <- time.After(time.Second):
+		t.Fatal("a busy room shouldn't hold up the clock")
+	}
+}
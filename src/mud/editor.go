@@ -0,0 +1,155 @@
+package mud
+
+import ("fmt"
+	"strconv"
+	"strings")
+
+// EditBuffer holds the lines of text being edited
+type EditBuffer struct {
+	Lines []string
+}
+
+func NewEditBuffer(text string) *EditBuffer {
+	b := new(EditBuffer)
+	if text != "" {
+		b.Lines = strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+	}
+	return b
+}
+
+func (b *EditBuffer) Text() string { return strings.Join(b.Lines, "\n") }
+
+func (b *EditBuffer) Append(line string) { b.Lines = append(b.Lines, line) }
+
+func (b *EditBuffer) checkLine(n int) error {
+	if n < 1 || n > len(b.Lines) {
+		return fmt.Errorf("no line %d", n)
+	}
+	return nil
+}
+
+// Insert puts line before line n, counting from 1. n may be one past
+// the end, to append.
+func (b *EditBuffer) Insert(n int, line string) error {
+	if n != len(b.Lines) + 1 {
+		if err := b.checkLine(n); err != nil { return err }
+	}
+	b.Lines = append(b.Lines[:n-1], append([]string{line}, b.Lines[n-1:]...)...)
+	return nil
+}
+
+func (b *EditBuffer) Delete(n int) error {
+	if err := b.checkLine(n); err != nil { return err }
+	b.Lines = append(b.Lines[:n-1], b.Lines[n:]...)
+	return nil
+}
+
+func (b *EditBuffer) Replace(n int, line string) error {
+	if err := b.checkLine(n); err != nil { return err }
+	b.Lines[n-1] = line
+	return nil
+}
+
+// Numbered is the text with line numbers, for previewing
+func (b *EditBuffer) Numbered() string {
+	text := ""
+	for i, line := range(b.Lines) {
+		text += fmt.Sprintf("%3d| %s\n", i+1, line)
+	}
+	return text
+}
+
+const editorHelp = `Editing. Lines you type are added to the end. Commands:
+  .p             preview, with line numbers
+  .i [n] [text]  insert text before line n
+  .r [n] [text]  replace line n
+  .d [n]         delete line n
+  .c             clear everything
+  .s             save and stop editing
+  .q             stop editing without saving
+  .h             this help
+`
+
+/*
+ LineEditor is a ConnectionState for editing multi-line text, such as
+ a room's description. Use Player.Edit to start one. It runs on the
+ connection's goroutine, so saving is queued as an action in room.
+ */
+type LineEditor struct {
+	ConnectionState
+	buffer *EditBuffer
+	room *Room
+	onSave func(string)
+}
+
+func (e *LineEditor) Name() string { return "line editor" }
+func (e *LineEditor) Init(c *UserConnection) {
+	c.Write(editorHelp + e.buffer.Numbered() + "] ")
+}
+
+func (e *LineEditor) Respond(c *UserConnection) bool {
+	line := <- c.FromUser
+	if !strings.HasPrefix(line, ".") {
+		e.buffer.Append(line)
+		c.Write("] ")
+		return true
+	}
+
+	fields := strings.SplitN(line, " ", 3)
+	n := 0
+	if len(fields) > 1 {
+		n, _ = strconv.Atoi(fields[1])
+	}
+	text := ""
+	if len(fields) > 2 {
+		text = fields[2]
+	}
+	var err error
+	switch fields[0] {
+	case ".p":
+		c.Write(e.buffer.Numbered())
+	case ".i":
+		err = e.buffer.Insert(n, text)
+	case ".r":
+		err = e.buffer.Replace(n, text)
+	case ".d":
+		err = e.buffer.Delete(n)
+	case ".c":
+		e.buffer.Lines = nil
+	case ".s":
+		e.room.Act(EditSaveAction{onSave: e.onSave, text: e.buffer.Text()})
+		c.Write("Saved.\n> ")
+		return false
+	case ".q":
+		c.Write("Discarded.\n> ")
+		return false
+	default:
+		c.Write(editorHelp)
+	}
+	if err != nil {
+		c.Write("Error: " + err.Error() + ".\n")
+	}
+	c.Write("] ")
+	return true
+}
+
+// EditSaveAction hands text saved in a LineEditor to its onSave
+type EditSaveAction struct {
+	InterObjectAction
+	onSave func(string)
+	text string
+}
+
+func (a EditSaveAction) Targets() []PhysicalObject { return []PhysicalObject{} }
+func (a EditSaveAction) Source() PhysicalObject { return nil }
+func (a EditSaveAction) Exec() { a.onSave(a.text) }
+
+/*
+ Edit opens an editor on text for p. If p saves it, onSave is called
+ with the new text from the action queue of the room p is in now.
+ */
+func (p *Player) Edit(text string, onSave func(string)) {
+	p.editing = true
+	p.Conn.PushState(&LineEditor{buffer: NewEditBuffer(text),
+		room: p.room, onSave: onSave})
+}
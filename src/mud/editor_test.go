@@ -0,0 +1,78 @@
+package mud
+
+import ("io"
+	"net"
+	"reflect"
+	"testing"
+	"time")
+
+func TestEditBuffer(t *testing.T) {
+	b := NewEditBuffer("one\r\nthree")
+	if err := b.Insert(2, "two"); err != nil {
+		t.Errorf("insert failed: %s", err)
+	}
+	b.Insert(4, "four")
+	b.Replace(1, "One")
+	b.Delete(3)
+	if !reflect.DeepEqual(b.Lines, []string{"One", "two", "four"}) {
+		t.Errorf("unexpected lines %v", b.Lines)
+	}
+	if b.Numbered() != "  1| One\n  2| two\n  3| four\n" {
+		t.Errorf("unexpected preview:\n%s", b.Numbered())
+	}
+	if b.Delete(4) == nil || b.Insert(0, "zero") == nil {
+		t.Errorf("lines out of range should be errors")
+	}
+	if NewEditBuffer("").Text() != "" {
+		t.Errorf("an empty buffer should have no lines")
+	}
+}
+
+// testLoginState lets the first line through, then goes in band
+type testLoginState struct {
+	ConnectionState
+	loggedIn chan bool
+}
+
+func (s *testLoginState) Name() string { return "test login" }
+func (s *testLoginState) Init(c *UserConnection) {}
+func (s *testLoginState) Respond(c *UserConnection) bool {
+	<- c.FromUser
+	close(s.loggedIn)
+	return false
+}
+
+func TestEditorSavesThroughRoom(t *testing.T) {
+	r := NewRoom(testUniverse(), 0, "Old text.")
+	p := NewPlayer(r.universe, "Alice")
+	p.room = r
+	server, client := net.Pipe()
+	defer client.Close()
+	go io.Copy(io.Discard, client)
+	login := &testLoginState{loggedIn: make(chan bool)}
+	p.Conn = NewUserConnection(server, login)
+	p.Conn.OnDisconnect = func() {}
+	client.Write([]byte("Alice\n"))
+	<- login.loggedIn
+
+	saved := make(chan string, 1)
+	p.Edit(r.text, func(text string) { saved <- text })
+	if !p.editing {
+		t.Error("the editor should be left to write the prompt")
+	}
+	client.Write([]byte(".c\n"))
+	client.Write([]byte("New text.\n"))
+	client.Write([]byte(".s\n"))
+	select {
+	case text := <- saved:
+		if text != "New text." {
+			t.Errorf("saved %q", text)
+		}
+	case <- time.After(time.Second):
+		t.Fatal("the edit wasn't saved")
+	}
+	client.Write([]byte("look\n"))
+	if line := <- p.Conn.InBand; line != "look" {
+		t.Errorf("after saving, input should go to the player, got %q", line)
+	}
+}
@@ -0,0 +1,283 @@
+package mud
+
+import ("fmt"
+	"sort"
+	"strings")
+
+func init() {
+	GlobalCommands["wear"] = equipCommand("wear")
+	GlobalCommands["wield"] = equipCommand("wield")
+	GlobalCommands["remove"] = equipCommand("remove")
+	GlobalCommands["equipment"] = equipment
+	GlobalCommands["eq"] = equipment
+
+	PlayerPerceptions["equip"] = doesPerceiveEquip
+}
+
+// Where a player can wear things, in the order equipment lists them
+var EquipmentSlots = []string{ "head", "neck", "body", "arms", "hands",
+	"waist", "legs", "feet", "wield", "hold" }
+
+/*
+ Wearable is implemented by PhysicalObjects which can be worn, wielded
+ or held. Slots names the EquipmentSlots the object fits; objects
+ with none can't be equipped.
+ */
+type Wearable interface {
+	PhysicalObject
+	Slots() []string
+}
+
+/*
+ Modifier is implemented by objects which change their wearer's stats
+ while equipped, e.g. {"strength": 2}.
+ */
+type Modifier interface {
+	Modifiers() map[string]int
+}
+
+func (p *Player) Equipped() []PhysicalObject {
+	equipped := []PhysicalObject{}
+	for _, slot := range(EquipmentSlots) {
+		if o, ok := p.equipment[slot]; ok {
+			equipped = append(equipped, o)
+		}
+	}
+	return equipped
+}
+
+// Modifier is how much p's equipment and buffs change stat
+func (p *Player) Modifier(stat string) int {
+	total := p.buffModifier(stat)
+	for _, o := range(p.Equipped()) {
+		if m, ok := o.(Modifier); ok {
+			total += m.Modifiers()[stat]
+		}
+	}
+	return total
+}
+
+/*
+ freeSlot picks where o goes: slot if given, or else the first free
+ one it fits (other than wield, which is only for wield). It returns
+ a message explaining why there is none.
+ */
+func (p *Player) freeSlot(o Wearable, slot string, verb string) (string, string) {
+	fits := []string{}
+	for _, s := range(o.Slots()) {
+		if (verb == "wield") == (s == "wield") && (slot == "" || s == slot) {
+			fits = append(fits, s)
+		}
+	}
+	if len(fits) == 0 {
+		if verb == "wield" {
+			return "", "You can't wield that.\n"
+		}
+		if slot != "" {
+			return "", "You can't wear that on your " + slot + ".\n"
+		}
+		return "", "You can't wear that.\n"
+	}
+	for _, s := range(fits) {
+		if _, taken := p.equipment[s]; !taken {
+			return s, ""
+		}
+	}
+	if verb == "wield" {
+		return "", "You are already wielding something.\n"
+	}
+	return "", "You are already wearing something on your " + fits[0] + ".\n"
+}
+
+/*
+ Equip puts o, which p carries, in slot. o is saved first if it never
+ has been, so that p's record can name it.
+ */
+func (p *Player) Equip(o PhysicalObject, slot string) {
+	savedName(o)
+	p.inventory.Remove(o)
+	p.equipment[slot] = o
+}
+
+// Unequip takes off what p has in slot, returning it to the inventory
+func (p *Player) Unequip(slot string) PhysicalObject {
+	o := p.equipment[slot]
+	delete(p.equipment, slot)
+	p.Add(o)
+	return o
+}
+
+/*
+ canRemove says why p can't take off o, called name, or "" if they
+ can. o goes back to the inventory, and the strength it gives is lost.
+ */
+func (p *Player) canRemove(o PhysicalObject, name string) string {
+	strength := p.Strength()
+	if m, ok := o.(Modifier); ok {
+		strength -= m.Modifiers()["strength"]
+	}
+	switch {
+	case p.Load() + Load(o) > maxLoad(strength):
+		return noSpaceMsg(name)
+	case p.CarriedWeight() > maxWeight(strength):
+		return "Without " + name + " you couldn't carry everything.\n"
+	}
+	return ""
+}
+
+func (p *Player) slotOf(o PhysicalObject) (string, bool) {
+	for slot, worn := range(p.equipment) {
+		if worn == o { return slot, true }
+	}
+	return "", false
+}
+
+func (p *Player) equippedSlot(name string) (string, bool) {
+	for slot, o := range(p.equipment) {
+		for _, handle := range(o.TextHandles()) {
+			if handle == name { return slot, true }
+		}
+	}
+	return "", false
+}
+
+// equipmentStrings persists saved equipment as "slot=object:id"
+func equipmentStrings(equipment map[string]PhysicalObject) []string {
+	strs := []string{}
+	for slot, o := range(equipment) {
+		if persister, ok := o.(Persister); ok && !unsaved(persister) {
+			strs = append(strs, slot + "=" + persister.DBFullName())
+		}
+	}
+	sort.Strings(strs)
+	return strs
+}
+
+func loadEquipment(u *Universe, strs []string) map[string]PhysicalObject {
+	equipment := make(map[string]PhysicalObject)
+	for slot, name := range(parsePropertyStrings(strs)) {
+		if o, ok := LoadArbitrary(u, name).(PhysicalObject); ok {
+			equipment[slot] = o
+		} else {
+			Log("[warn] couldn't load equipment", name)
+		}
+	}
+	return equipment
+}
+
+// LongDescription lists what p is wearing, for those looking at them
+func (p *Player) LongDescription() string {
+	text := p.Description()
+	for _, slot := range(EquipmentSlots) {
+		if o, ok := p.equipment[slot]; ok {
+			text += fmt.Sprintf("\n  %-7s%s", slot + ":", o.Description())
+		}
+	}
+	return text
+}
+
+type EquipStimulus struct {
+	Stimulus
+	player *Player
+	obj PhysicalObject
+	verb string
+}
+
+var equipThirdPerson = map[string]string{
+	"wear": "wears", "wield": "wields", "remove": "removes",
+}
+
+func (s EquipStimulus) StimType() string { return "equip" }
+func (s EquipStimulus) Description(p Perceiver) string {
+	who, verb := s.player.name, equipThirdPerson[s.verb]
+	if playerReceiver, ok := p.(*Player); ok && s.player.id == playerReceiver.id {
+		who, verb = "You", s.verb
+	}
+	return who + " " + verb + " " + strings.ToLower(s.obj.Description()) + ".\n"
+}
+
+func doesPerceiveEquip(p Player, s Stimulus) bool { return true }
+
+type EquipAction struct {
+	InterObjectAction
+	player *Player
+	verb string
+	what string
+	slot string
+}
+
+func (a EquipAction) Targets() []PhysicalObject { return []PhysicalObject{} }
+func (a EquipAction) Source() PhysicalObject { return a.player }
+func (a EquipAction) Exec() {
+	player := a.player
+	if a.verb == "remove" {
+		slot, ok := player.equippedSlot(a.what)
+		if !ok {
+			player.WriteString("You aren't wearing " + a.what + ".\n")
+			return
+		}
+		if msg := player.canRemove(player.equipment[slot], a.what); msg != "" {
+			player.WriteString(msg)
+			return
+		}
+		o := player.Unequip(slot)
+		player.Save()
+		player.room.Broadcast(EquipStimulus{player: player, obj: o, verb: "remove"})
+		return
+	}
+	o, ok := player.PerceiveList(InvContext)[a.what]
+	if !ok {
+		player.WriteString(a.what + " not in your inventory.\n")
+		return
+	}
+	wearable, ok := o.(Wearable)
+	if !ok {
+		player.WriteString("You can't " + a.verb + " that.\n")
+		return
+	}
+	slot, msg := player.freeSlot(wearable, a.slot, a.verb)
+	if msg != "" {
+		player.WriteString(msg)
+		return
+	}
+	player.Equip(o, slot)
+	player.Save()
+	player.room.Broadcast(EquipStimulus{player: player, obj: o, verb: a.verb})
+}
+
+func equipCommand(verb string) Command {
+	return func(p *Player, args []string) {
+		if len(args) == 0 {
+			usage := " [object]"
+			if verb == "wear" {
+				usage += "[ on [place]]"
+			}
+			p.WriteString(strings.Title(verb) + " usage: " + verb + usage + ".\n")
+			return
+		}
+		what, slot, on := splitOn(args, "on")
+		if !on || verb != "wear" {
+			what, slot = strings.ToLower(strings.Join(args, " ")), ""
+		}
+		p.room.Act(EquipAction{player: p, verb: verb,
+			what: what, slot: slot})
+	}
+}
+
+func equipment(p *Player, args []string) {
+	p.WriteString(Divider())
+	p.WriteString("Equipment:\n")
+	if len(p.equipment) == 0 {
+		p.WriteString("  nothing\n")
+	}
+	for _, slot := range(EquipmentSlots) {
+		if o, ok := p.equipment[slot]; ok {
+			p.WriteString(fmt.Sprintf("  %-7s%s\n", slot + ":", o.Description()))
+		}
+	}
+	if mod := p.Modifier("strength"); mod != 0 {
+		p.WriteString(fmt.Sprintf("Strength %d (%+d from equipment and effects).\n",
+			p.Strength(), mod))
+	}
+	p.WriteString(Divider())
+}
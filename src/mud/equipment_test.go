@@ -0,0 +1,118 @@
+package mud
+
+import ("strconv"
+	"testing")
+
+type testWearable struct {
+	testObject
+	slots []string
+	mods map[string]int
+}
+
+func (o *testWearable) Slots() []string { return o.slots }
+func (o *testWearable) Modifiers() map[string]int { return o.mods }
+
+// savedWearable persists, loading back as itself from savedWearables
+type savedWearable struct {
+	testWearable
+	id int
+}
+
+var savedWearables = make(map[int]*savedWearable)
+
+func (o *savedWearable) DBFullName() string { return "testWearable:" + strconv.Itoa(o.id) }
+func (o *savedWearable) PersistentValues() map[string]interface{} { return nil }
+func (o *savedWearable) Save() string {
+	if o.id == 0 {
+		o.id = len(savedWearables) + 100
+		savedWearables[o.id] = o
+	}
+	return strconv.Itoa(o.id)
+}
+
+func init() {
+	Loaders["testWearable"] = func(u *Universe, id int) interface{} {
+		return savedWearables[id]
+	}
+}
+
+func TestEquipSlots(t *testing.T) {
+	p := &Player{strength: DefaultStrength,
+		inventory: NewFlexContainer("PhysicalObjects"),
+		equipment: make(map[string]PhysicalObject)}
+	belt := &testWearable{slots: []string{"waist"}, mods: map[string]int{"strength": 5}}
+	sword := &testWearable{slots: []string{"wield"}}
+
+	if _, msg := p.freeSlot(sword, "", "wear"); msg != "You can't wear that.\n" {
+		t.Errorf("wearing a sword gave %q", msg)
+	}
+	if _, msg := p.freeSlot(belt, "head", "wear"); msg == "" {
+		t.Error("belt shouldn't go on the head")
+	}
+	slot, msg := p.freeSlot(belt, "", "wear")
+	if slot != "waist" || msg != "" {
+		t.Fatalf("belt should go on the waist, got %q %q", slot, msg)
+	}
+	p.Equip(belt, slot)
+	if p.Strength() != 15 || p.MaxWeight() != 75 {
+		t.Errorf("belt should add 5 strength, got %d", p.Strength())
+	}
+	if _, msg := p.freeSlot(belt, "", "wear"); msg == "" {
+		t.Error("waist should be taken")
+	}
+	anvil := &testThing{handle: "anvil", weight: 60}
+	p.Add(anvil)
+	if msg := p.canRemove(belt, "belt"); msg == "" {
+		t.Error("the anvil is too heavy to carry without the belt")
+	}
+	p.inventory.Remove(anvil)
+	for i := 0; i < 10; i++ { p.Add(&testThing{handle: "pebble"}) }
+	if msg := p.canRemove(belt, "belt"); msg != noSpaceMsg("belt") {
+		t.Errorf("without the belt there's only room for 10, got %q", msg)
+	}
+	for _, o := range(p.Inventory()) { p.inventory.Remove(o) }
+	if got := equipmentStrings(p.equipment); len(got) != 0 {
+		t.Errorf("test objects don't persist, got %v", got)
+	}
+	p.Unequip("waist")
+	if p.Strength() != DefaultStrength || len(p.Inventory()) != 1 {
+		t.Error("removing the belt should return it to the inventory")
+	}
+}
+
+func TestEquipmentSaved(t *testing.T) {
+	u := testUniverse()
+	p := NewPlayer(u, "Alice")
+	p.Save()
+	hat := &savedWearable{testWearable{slots: []string{"head"}}, 7}
+	savedWearables[hat.id] = hat
+	p.Add(hat)
+	p.Equip(hat, "head")
+	p.Save()
+
+	loaded := LoadPlayer(u, "Alice")
+	if loaded.id != p.id || loaded.equipment["head"] != PhysicalObject(hat) {
+		t.Errorf("reloaded player should wear the hat, got %v", loaded.equipment)
+	}
+	p.Unequip("head")
+	p.Save()
+	if loaded := LoadPlayer(u, "Alice"); len(loaded.equipment) != 0 {
+		t.Errorf("removed hat still worn after reloading: %v", loaded.equipment)
+	}
+}
+
+func TestEquipSavesNewObjects(t *testing.T) {
+	p := NewPlayer(testUniverse(), "Alice")
+	boots := &savedWearable{testWearable{slots: []string{"feet"}}, 0}
+	if got := equipmentStrings(map[string]PhysicalObject{"feet": boots}); len(got) != 0 || boots.id != 0 {
+		t.Errorf("listing equipment shouldn't save it, got %v", got)
+	}
+	p.Add(boots)
+	p.Equip(boots, "feet")
+	if boots.id == 0 || savedWearables[boots.id] != boots {
+		t.Error("equipping should save the boots")
+	}
+	if got := equipmentStrings(p.equipment); len(got) != 1 || got[0] != "feet=" + boots.DBFullName() {
+		t.Errorf("the boots should be listed, got %v", got)
+	}
+}
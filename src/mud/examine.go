@@ -0,0 +1,110 @@
+package mud
+
+import ("sort"
+	"strings")
+
+func init() {
+	GlobalCommands["examine"] = examine
+	GlobalCommands["rextra"] = roomExtraCommand
+}
+
+/*
+ LongDescriber is implemented by PhysicalObjects with more to say when
+ examined than their one-line Description.
+ */
+type LongDescriber interface {
+	LongDescription() string
+}
+
+// LongDescriptionOf is o's long description, or its Description
+func LongDescriptionOf(o PhysicalObject) string {
+	if ld, ok := o.(LongDescriber); ok && ld.LongDescription() != "" {
+		return ld.LongDescription()
+	}
+	return o.Description()
+}
+
+/*
+ ExtraDescription is the text for a keyword naming scenery in the room,
+ such as "fountain", which isn't an object of its own.
+ */
+func (r *Room) ExtraDescription(keyword string) (string, bool) {
+	text, ok := r.extraDescs[strings.ToLower(keyword)]
+	return text, ok
+}
+
+// SetExtraDescription sets the text for keyword; "" removes it.
+func (r *Room) SetExtraDescription(keyword string, text string) {
+	keyword = strings.ToLower(keyword)
+	if text == "" {
+		delete(r.extraDescs, keyword)
+	} else {
+		r.extraDescs[keyword] = text
+	}
+}
+
+func (r *Room) ExtraKeywords() []string {
+	keywords := []string{}
+	for keyword := range(r.extraDescs) { keywords = append(keywords, keyword) }
+	sort.Strings(keywords)
+	return keywords
+}
+
+/*
+ LookAt describes the thing p calls target: something p can see or
+ carries, or failing that one of the room's extra descriptions.
+ */
+func LookAt(p *Player, target string) {
+	target = strings.ToLower(target)
+	if o, ok := p.PerceiveList(LookContext)[target]; ok {
+		p.WriteString(LongDescriptionOf(o) + "\n")
+		return
+	}
+	if p.room.IsDark() {
+		p.WriteString("It is too dark to see.\n")
+		return
+	}
+	if text, ok := p.room.ExtraDescription(target); ok {
+		p.WriteString(text + "\n")
+		return
+	}
+	p.WriteString("You see no " + target + " here.\n")
+}
+
+func examine(p *Player, args []string) {
+	if len(args) == 0 {
+		p.WriteString("Examine usage: examine [thing].\n")
+		return
+	}
+	target := strings.ToLower(strings.Join(args, " "))
+	LookAt(p, target)
+	if o, ok := p.PerceiveList(LookContext)[target]; ok {
+		p.WriteString(MeasuresOf(o))
+		if condition := ConditionOf(o); condition != "" {
+			p.WriteString("Condition: " + condition + ".\n")
+		}
+	}
+}
+
+func roomExtraCommand(p *Player, args []string) {
+	r := p.room
+	if len(args) == 0 {
+		if keywords := r.ExtraKeywords(); len(keywords) > 0 {
+			p.WriteString("Extra descriptions: " +
+				strings.Join(keywords, ", ") + "\n")
+		} else {
+			p.WriteString("This room has no extra descriptions.\n")
+		}
+		return
+	}
+	if !r.CanEdit(p) {
+		p.WriteString("You can't change this room.\n")
+		return
+	}
+	EditExtraDescription(p, r, args[0], strings.Join(args[1:], " "))
+	if len(args) > 1 {
+		p.WriteString("Extra description " + args[0] + " set.\n")
+	} else {
+		p.WriteString("Extra description " + args[0] + " removed.\n")
+	}
+}
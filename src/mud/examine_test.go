@@ -0,0 +1,58 @@
+package mud
+
+import "testing"
+
+type testStatue struct {
+	testThing
+	long string
+}
+
+func (s *testStatue) LongDescription() string { return s.long }
+
+func TestLookAtExtraDescriptions(t *testing.T) {
+	r := quietRoom(testUniverse())
+	r.SetExtraDescription("Fountain", "Water splashes over mossy stone.")
+	p, socket := testPlayer(r)
+
+	LookAt(p, "FOUNTAIN")
+	if socket.written != "Water splashes over mossy stone.\n" {
+		t.Errorf("looking at the fountain gave %q", socket.written)
+	}
+	socket.written = ""
+	LookAt(p, "bench")
+	if socket.written != "You see no bench here.\n" {
+		t.Errorf("looking at nothing gave %q", socket.written)
+	}
+	socket.written = ""
+	r.SetProperty("light", "0")
+	LookAt(p, "fountain")
+	if socket.written != "It is too dark to see.\n" {
+		t.Errorf("looking in the dark gave %q", socket.written)
+	}
+	r.SetExtraDescription("fountain", "")
+	if _, ok := r.ExtraDescription("fountain"); ok {
+		t.Error("an empty extra description should remove it")
+	}
+}
+
+func TestLookAtLongDescriptions(t *testing.T) {
+	r := quietRoom(testUniverse())
+	statue := &testStatue{long: "A marble statue of a forgotten king."}
+	statue.name, statue.handle = "A statue", "statue"
+	plinth := &testStatue{}
+	plinth.name, plinth.handle = "A plinth", "plinth"
+	r.AddChild(statue)
+	r.AddChild(plinth)
+	r.SetExtraDescription("statue", "The scenery shouldn't win.")
+	p, socket := testPlayer(r)
+
+	LookAt(p, "statue")
+	if socket.written != "A marble statue of a forgotten king.\n" {
+		t.Errorf("looking at the statue gave %q", socket.written)
+	}
+	socket.written = ""
+	LookAt(p, "plinth")
+	if socket.written != "A plinth\n" {
+		t.Errorf("without a long description, looking gave %q", socket.written)
+	}
+}
@@ -0,0 +1,73 @@
+package mud
+
+var oppositeExits = map[string]string{
+	"north": "south", "south": "north",
+	"east": "west", "west": "east",
+	"northeast": "southwest", "southwest": "northeast",
+	"northwest": "southeast", "southeast": "northwest",
+	"up": "down", "down": "up",
+	"in": "out", "out": "in",
+}
+
+// Where someone arriving through an exit is said to come from
+var arrivalPlaces = map[string]string{
+	"north": "the north", "south": "the south",
+	"east": "the east", "west": "the west",
+	"northeast": "the northeast", "southwest": "the southwest",
+	"northwest": "the northwest", "southeast": "the southeast",
+	"up": "above", "down": "below",
+	"in": "inside", "out": "outside",
+}
+
+/*
+ OppositeExit returns the conventional name for the way back through
+ an exit, e.g. "west" for "east". ok is false for exit names without
+ a conventional opposite.
+ */
+func OppositeExit(name string) (opposite string, ok bool) {
+	opposite, ok = oppositeExits[name]
+	return
+}
+
+/*
+ DepartMessage describes someone leaving through this exit, e.g.
+ "leaves east" or a custom message such as "climbs the ladder".
+ */
+func (r *RoomExitInfo) DepartMessage() string {
+	if msg := r.exit.Options().Depart[r.exitSide]; msg != "" {
+		return msg
+	}
+	if _, isCompass := arrivalPlaces[r.Name()]; isCompass {
+		return "leaves " + r.Name()
+	}
+	return "leaves"
+}
+
+/*
+ ArriveMessage describes someone arriving on the other side of this
+ exit, e.g. "arrives from the west".
+ */
+func (r *RoomExitInfo) ArriveMessage() string {
+	if msg := r.exit.Options().Arrive[r.exitSide]; msg != "" {
+		return msg
+	}
+	if place, isCompass := arrivalPlaces[r.ReverseName()]; isCompass {
+		return "arrives from " + place
+	}
+	return "arrives"
+}
+
+/*
+ PortalRoomConnectCreator connects two rooms by a named portal
+ (archway, wardrobe, portal...), with an "enter [name]" exit on
+ SideA and a "leave [name]" exit on SideB.
+ */
+func PortalRoomConnectCreator(name string) RoomConnCreator {
+	var opts ExitOptions
+	opts.Depart[SideA] = "steps into the " + name
+	opts.Arrive[SideA] = "steps out of the " + name
+	opts.Depart[SideB] = "steps back through the " + name
+	opts.Arrive[SideB] = "steps out of the " + name
+	return WithExitOptions(
+		SimpleRoomConnectCreator("enter " + name, "leave " + name), opts)
+}
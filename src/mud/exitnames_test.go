@@ -0,0 +1,53 @@
+package mud
+
+import "testing"
+
+func TestDefaultMoveMessages(t *testing.T) {
+	a, b := testRoom(1), testRoom(2)
+	testConnect(a, b, SimpleRoomConnectCreator("east", "west"))
+	testConnect(a, b, SimpleRoomConnectCreator("climb rope", "descend"))
+	east, rope := a.exits[0], a.exits[1]
+	if east.DepartMessage() != "leaves east" || east.ArriveMessage() != "arrives from the west" {
+		t.Errorf("compass messages were %q and %q",
+			east.DepartMessage(), east.ArriveMessage())
+	}
+	if rope.DepartMessage() != "leaves" || rope.ArriveMessage() != "arrives" {
+		t.Errorf("named exit messages were %q and %q",
+			rope.DepartMessage(), rope.ArriveMessage())
+	}
+}
+
+func TestCustomMoveMessages(t *testing.T) {
+	u := testUniverse()
+	cellar, kitchen := quietRoom(u), quietRoom(u)
+	var opts ExitOptions
+	opts.Depart[SideA] = "climbs the ladder"
+	opts.Arrive[SideA] = "climbs up from the cellar"
+	ConnectWithConnCreator(WithExitOptions(
+		SimpleRoomConnectCreator("climb ladder", "climb down"), opts))(cellar, kitchen)
+	p, _ := testPlayer(cellar)
+
+	goExit(p, []string{"climb", "ladder"})
+	left := (<-cellar.stimuliBroadcast).Description(nil)
+	arrived := (<-kitchen.stimuliBroadcast).Description(nil)
+	if left != "Alice climbs the ladder.\n" || arrived != "Alice climbs up from the cellar.\n" {
+		t.Errorf("custom messages were %q and %q", left, arrived)
+	}
+	back := kitchen.exits[0]
+	if back.DepartMessage() != "leaves" || back.ArriveMessage() != "arrives" {
+		t.Errorf("the way back should use the defaults, got %q and %q",
+			back.DepartMessage(), back.ArriveMessage())
+	}
+}
+
+func TestPortalMessages(t *testing.T) {
+	a, b := testRoom(1), testRoom(2)
+	testConnect(a, b, PortalRoomConnectCreator("wardrobe"))
+	into, out := a.exits[0], b.exits[0]
+	if into.Name() != "enter wardrobe" || into.DepartMessage() != "steps into the wardrobe" {
+		t.Errorf("entering was %q: %q", into.Name(), into.DepartMessage())
+	}
+	if out.Name() != "leave wardrobe" || out.DepartMessage() != "steps back through the wardrobe" {
+		t.Errorf("leaving was %q: %q", out.Name(), out.DepartMessage())
+	}
+}
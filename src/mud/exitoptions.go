@@ -0,0 +1,175 @@
+package mud
+
+import ("sort"
+	"strconv"
+	"strings"
+	"sync")
+
+func init() {
+	GlobalCommands["search"] = search
+
+	ExitConditions["carrying"] = carryingCondition
+	ExitConditions["money"] = moneyCondition
+}
+
+/*
+ ExitCondition decides whether p may pass through a conditional
+ exit. arg is the remainder of the condition string after the
+ condition name, e.g. "brass key" for "carrying brass key".
+ */
+type ExitCondition func(p *Player, arg string) bool
+
+// Map of [condition name] -> ExitCondition, for conditional exits
+var ExitConditions = make(map[string]ExitCondition)
+
+/*
+ ExitOptions change how a RoomConnection is seen and passed.
+
+ A OneWay connection only has an exit on SideA. A Hidden connection
+ is not listed by ExitNames, or usable, until a player discovers it
+ with "search". A connection with a Condition can only be passed by
+ players for whom the named ExitCondition holds; others are shown
+ Refusal. Depart and Arrive override the default movement messages
+ built by RoomExitInfo.
+ */
+type ExitOptions struct {
+	OneWay bool
+	Hidden bool
+	Condition string
+	Refusal string
+	// Messages shown to the rooms left and entered when travelling
+	// from each side, e.g. Depart[SideA] = "climbs the ladder",
+	// Arrive[SideA] = "climbs up from below".
+	Depart [2]string
+	Arrive [2]string
+	discoveredBy map[string]bool
+}
+
+/*
+ discoveries guards the contents of every connection's discoveredBy,
+ which players searching write while the universe reads them to save.
+ It isn't kept in ExitOptions, as those are copied by value.
+ */
+var discoveries sync.RWMutex
+
+func (o *ExitOptions) Discovered(p *Player) bool {
+	discoveries.RLock()
+	defer discoveries.RUnlock()
+	return o.discoveredBy[p.name]
+}
+
+func (o *ExitOptions) Discover(p *Player) {
+	discoveries.Lock()
+	defer discoveries.Unlock()
+	if o.discoveredBy == nil {
+		o.discoveredBy = make(map[string]bool)
+	}
+	o.discoveredBy[p.name] = true
+}
+
+// discoverers lists who has discovered the connection, in order
+func (o *ExitOptions) discoverers() []string {
+	discoveries.RLock()
+	defer discoveries.RUnlock()
+	names := []string{}
+	for name := range(o.discoveredBy) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (o *ExitOptions) persistentValues(vals map[string]interface{}) {
+	vals["oneWay"] = strconv.FormatBool(o.OneWay)
+	vals["hidden"] = strconv.FormatBool(o.Hidden)
+	vals["condition"] = o.Condition
+	vals["refusal"] = o.Refusal
+	vals["aDepart"], vals["aArrive"] = o.Depart[SideA], o.Arrive[SideA]
+	vals["bDepart"], vals["bArrive"] = o.Depart[SideB], o.Arrive[SideB]
+	vals["discoveredBy"] = o.discoverers()
+}
+
+func loadExitOptions(vals Pvals) ExitOptions {
+	var opts ExitOptions
+	opts.OneWay, _ = strconv.ParseBool(stringVal(vals, "oneWay"))
+	opts.Hidden, _ = strconv.ParseBool(stringVal(vals, "hidden"))
+	opts.Condition = stringVal(vals, "condition")
+	opts.Refusal = stringVal(vals, "refusal")
+	opts.Depart[SideA] = stringVal(vals, "aDepart")
+	opts.Arrive[SideA] = stringVal(vals, "aArrive")
+	opts.Depart[SideB] = stringVal(vals, "bDepart")
+	opts.Arrive[SideB] = stringVal(vals, "bArrive")
+	if names, ok := vals["discoveredBy"].([]string); ok {
+		opts.discoveredBy = make(map[string]bool)
+		for _, name := range(names) {
+			opts.discoveredBy[name] = true
+		}
+	}
+	return opts
+}
+
+func stringVal(vals Pvals, key string) string {
+	s, _ := vals[key].(string)
+	return s
+}
+
+/*
+ WithExitOptions wraps exitGen so that the connections it creates
+ have opts, e.g.
+
+	ConnectWithConnCreator(WithExitOptions(UpDownRoomConnection,
+		ExitOptions{Hidden: true}))
+ */
+func WithExitOptions(exitGen RoomConnCreator, opts ExitOptions) RoomConnCreator {
+	return func() RoomConnection {
+		rc := exitGen()
+		*rc.Options() = opts
+		return rc
+	}
+}
+
+// CheckExitCondition evaluates a condition string such as "money 100"
+func CheckExitCondition(p *Player, condition string) bool {
+	parts := strings.SplitN(condition, " ", 2)
+	test, ok := ExitConditions[parts[0]]
+	if !ok {
+		Log("[WARN] unknown exit condition", condition)
+		return false
+	}
+	arg := ""
+	if len(parts) > 1 {
+		arg = parts[1]
+	}
+	return test(p, arg)
+}
+
+func carryingCondition(p *Player, arg string) bool {
+	_, ok := p.PerceiveList(InvContext)[arg]
+	return ok
+}
+
+func moneyCondition(p *Player, arg string) bool {
+	amount, err := strconv.Atoi(arg)
+	return err == nil && p.money >= Currency(amount)
+}
+
+// VisibleTo is false for hidden exits p hasn't discovered
+func (r *RoomExitInfo) VisibleTo(p *Player) bool {
+	opts := r.exit.Options()
+	return !opts.Hidden || (p != nil && opts.Discovered(p))
+}
+
+func search(p *Player, args []string) {
+	found := false
+	for _, exit := range(p.room.exits) {
+		opts := exit.exit.Options()
+		if opts.Hidden && !opts.Discovered(p) {
+			opts.Discover(p)
+			p.WriteString("You discover a hidden exit: " + exit.Name() + "!\n")
+			found = true
+		}
+	}
+	if !found {
+		p.WriteString("You search around but find nothing.\n")
+	}
+}
@@ -0,0 +1,76 @@
+package mud
+
+import "testing"
+
+func TestOneWayExit(t *testing.T) {
+	u := testUniverse()
+	top, bottom := quietRoom(u), quietRoom(u)
+	rc := ConnectWithConnCreator(WithExitOptions(SimpleRoomConnectCreator("down", "up"),
+		ExitOptions{OneWay: true}))(top, bottom)
+	if len(top.exits) != 1 || len(bottom.exits) != 0 {
+		t.Fatalf("a one-way exit should only be on side A, got %d and %d",
+			len(top.exits), len(bottom.exits))
+	}
+	p, _ := testPlayer(top)
+	if ok, _ := rc.CanPass(p, SideA); !ok {
+		t.Error("a one-way exit should be passable from side A")
+	}
+	if ok, _ := rc.CanPass(p, SideB); ok {
+		t.Error("a one-way exit shouldn't be passable from side B")
+	}
+}
+
+func TestHiddenExitFoundBySearch(t *testing.T) {
+	u := testUniverse()
+	library, passage := quietRoom(u), quietRoom(u)
+	ConnectWithConnCreator(WithExitOptions(SimpleRoomConnectCreator("behind shelf", "out"),
+		ExitOptions{Hidden: true}))(library, passage)
+	p, socket := testPlayer(library)
+	bob, _ := testPlayer(library)
+	bob.name = "Bob"
+
+	if names := library.ExitNames(p); names != "" {
+		t.Errorf("a hidden exit shouldn't be listed, got %q", names)
+	}
+	goExit(p, []string{"behind", "shelf"})
+	if p.room != library || socket.written != "No visible exit behind shelf.\n" {
+		t.Errorf("a hidden exit shouldn't be usable, got %q", socket.written)
+	}
+	socket.written = ""
+	search(p, nil)
+	if socket.written != "You discover a hidden exit: behind shelf!\n" {
+		t.Errorf("search should find the exit, got %q", socket.written)
+	}
+	if library.ExitNames(p) != "behind shelf" || library.ExitNames(bob) != "" {
+		t.Error("the exit should only be shown to whoever found it")
+	}
+	goExit(p, []string{"behind", "shelf"})
+	if p.room != passage {
+		t.Error("a discovered exit should be usable")
+	}
+}
+
+func TestConditionalExit(t *testing.T) {
+	u := testUniverse()
+	gate, city := quietRoom(u), quietRoom(u)
+	ConnectWithConnCreator(WithExitOptions(SimpleRoomConnectCreator("north", "south"),
+		ExitOptions{Condition: "money 10", Refusal: "The guard wants a toll."}))(gate, city)
+	ConnectWithConnCreator(WithExitOptions(SimpleRoomConnectCreator("east", "west"),
+		ExitOptions{Condition: "carrying lamp"}))(gate, city)
+	p, socket := testPlayer(gate)
+
+	goExit(p, []string{"north"})
+	if p.room != gate || socket.written != "The guard wants a toll.\n" {
+		t.Errorf("the toll should be refused, got %q", socket.written)
+	}
+	socket.written = ""
+	goExit(p, []string{"east"})
+	if p.room != gate || socket.written != "Something prevents you from going that way.\n" {
+		t.Errorf("without a lamp the way should be refused, got %q", socket.written)
+	}
+	p.AdjustMoney(10)
+	goExit(p, []string{"north"})
+	if p.room != city {
+		t.Error("with the toll the player should pass")
+	}
+}
@@ -0,0 +1,260 @@
+package mud
+
+import ("fmt"
+	"strconv"
+	"sync")
+
+func init() {
+	PersistentKeys["gameClock"] = []string{ "id", "minutes" }
+
+	GlobalCommands["time"] = showTime
+
+	PlayerPerceptions["daylight"] = doesPerceiveDaylight
+
+	DescribeHooks = append(DescribeHooks, describeTimeOfDay)
+}
+
+// The game calendar: four seasons of thirty days make a year.
+const (
+	MinutesPerHour = 60
+	HoursPerDay = 24
+	DaysPerSeason = 30
+	DawnHour = 6
+	DuskHour = 18
+)
+
+var Seasons = []string{ "spring", "summer", "autumn", "winter" }
+
+// Times of day, as returned by GameTime.Period
+const (
+	Dawn = "dawn"
+	Day = "day"
+	Dusk = "dusk"
+	Night = "night"
+)
+
+// GameTime is a moment in game time, counted in minutes from the
+// start of the first year.
+type GameTime int
+
+func (t GameTime) Minute() int { return int(t) % MinutesPerHour }
+func (t GameTime) Hour() int { return int(t) / MinutesPerHour % HoursPerDay }
+func (t GameTime) days() int { return int(t) / (MinutesPerHour * HoursPerDay) }
+
+// DayOfSeason counts from 1
+func (t GameTime) DayOfSeason() int { return t.days() % DaysPerSeason + 1 }
+func (t GameTime) Season() string {
+	return Seasons[t.days() / DaysPerSeason % len(Seasons)]
+}
+
+// Year counts from 1
+func (t GameTime) Year() int {
+	return t.days() / (DaysPerSeason * len(Seasons)) + 1
+}
+
+func (t GameTime) Period() string {
+	switch hour := t.Hour(); {
+	case hour == DawnHour:
+		return Dawn
+	case hour > DawnHour && hour < DuskHour:
+		return Day
+	case hour == DuskHour:
+		return Dusk
+	}
+	return Night
+}
+
+// ClockTime is the time of day as on a wall clock, e.g. "6:05 pm"
+func (t GameTime) ClockTime() string {
+	hour, suffix := t.Hour() % 12, "am"
+	if t.Hour() >= 12 {
+		suffix = "pm"
+	}
+	if hour == 0 {
+		hour = 12
+	}
+	return fmt.Sprintf("%d:%02d %s", hour, t.Minute(), suffix)
+}
+
+func (t GameTime) String() string {
+	return fmt.Sprintf("%s on day %d of %s, year %d",
+		t.ClockTime(), t.DayOfSeason(), t.Season(), t.Year())
+}
+
+/*
+ GameClock is a TimeListener turning heartbeats into game time, at
+ ticksPerMinute heartbeats to the game minute. Outdoor rooms are told
+ of dawn and dusk, and functions added with OnHour run as each game
+ hour begins. There is one clock, saved as gameClock:1.
+ */
+type GameClock struct {
+	Persister
+	universe *Universe
+	ping chan int
+	ticksPerMinute int
+	ticks int
+	lock sync.RWMutex
+	now GameTime
+	hourHooks []func(GameTime)
+	minuteHooks []func(GameTime)
+}
+
+// The clock starts at 8 am on the first day of spring
+const clockStart = GameTime(8 * MinutesPerHour)
+
+func newGameClock(u *Universe, ticksPerMinute int) *GameClock {
+	c := new(GameClock)
+	c.universe = u
+	c.ping = make(chan int)
+	c.ticksPerMinute = ticksPerMinute
+	c.now = clockStart
+	return c
+}
+
+/*
+ LoadGameClock loads the saved clock, or starts a new one, and adds it
+ to the universe so it hears heartbeats and is saved.
+ */
+func LoadGameClock(u *Universe, ticksPerMinute int) *GameClock {
+	c := newGameClock(u, ticksPerMinute)
+	if saved, _ := u.Store.KeyExists(c.DBFullName() + ":minutes"); saved {
+		vals := u.Store.LoadStructure(PersistentKeys["gameClock"],
+			c.DBFullName())
+		minutes, _ := strconv.Atoi(stringVal(vals, "minutes"))
+		c.now = GameTime(minutes)
+	}
+	u.Clock = c
+	u.Add(c)
+	go c.run()
+	return c
+}
+
+func (c *GameClock) Ping() chan int { return c.ping }
+func (c *GameClock) Now() GameTime {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.now
+}
+
+// OnHour adds f to the functions run at the start of every game hour
+func (c *GameClock) OnHour(f func(GameTime)) {
+	c.hourHooks = append(c.hourHooks, f)
+}
+
+// OnMinute adds f to the functions run as each game minute passes
+func (c *GameClock) OnMinute(f func(GameTime)) {
+	c.minuteHooks = append(c.minuteHooks, f)
+}
+
+func (c *GameClock) run() {
+	for {
+		<- c.ping
+		c.ticks++
+		if c.ticks >= c.ticksPerMinute {
+			c.ticks = 0
+			c.Advance(1)
+		}
+	}
+}
+
+/*
+ Advance moves the clock on by some game minutes, running minute and
+ hourly hooks and announcing dawn and dusk for each hour passed. Rooms
+ too busy to hear of dawn or dusk miss it rather than stop the clock.
+ */
+func (c *GameClock) Advance(minutes int) {
+	for i := 0; i < minutes; i++ {
+		c.lock.Lock()
+		c.now++
+		now := c.now
+		c.lock.Unlock()
+		for _, hook := range(c.minuteHooks) {
+			hook(now)
+		}
+		if now.Minute() != 0 {
+			continue
+		}
+		for _, hook := range(c.hourHooks) {
+			hook(now)
+		}
+		switch now.Hour() {
+		case DawnHour, DuskHour:
+			s := DaylightStimulus{period: now.Period()}
+			for _, r := range(c.universe.Rooms) {
+				if r.Outdoors() && !r.TryBroadcast(s) {
+					Log("[warn] room", r.id, "too busy to see", now.Period())
+				}
+			}
+		}
+	}
+}
+
+func (c *GameClock) PersistentValues() map[string]interface{} {
+	vals := make(map[string]interface{})
+	vals["id"] = "1"
+	vals["minutes"] = strconv.Itoa(int(c.Now()))
+	return vals
+}
+
+func (c *GameClock) Save() string {
+	return c.universe.Store.SaveStructure("gameClock", c.PersistentValues())
+}
+
+func (c *GameClock) DBFullName() string { return "gameClock:1" }
+
+// Outdoors is true for rooms (or zones) with the property outdoors=yes,
+// unless the room is flagged indoors
+func (r *Room) Outdoors() bool {
+	outdoors, _ := r.Property("outdoors")
+	return outdoors == "yes" && !r.HasFlag(FlagIndoors)
+}
+
+type DaylightStimulus struct {
+	Stimulus
+	period string
+}
+
+func (s DaylightStimulus) StimType() string { return "daylight" }
+func (s DaylightStimulus) Period() string { return s.period }
+func (s DaylightStimulus) Description(p Perceiver) string {
+	if s.period == Dawn {
+		return "The sun rises in the east.\n"
+	}
+	return "The sun sets in the west.\n"
+}
+
+func doesPerceiveDaylight(p Player, s Stimulus) bool { return true }
+
+var periodSky = map[string]string{
+	Dawn: "The sky is brightening with the dawn.",
+	Day: "",
+	Dusk: "The sky is reddening with the dusk.",
+	Night: "It is night.",
+}
+
+/*
+ describeTimeOfDay adds the room's text for the time of day, from the
+ property desc:<period> (e.g. desc:night). Outdoor rooms without one
+ get a line about the sky.
+ */
+func describeTimeOfDay(r *Room, p *Player) string {
+	if r.universe == nil || r.universe.Clock == nil {
+		return ""
+	}
+	period := r.universe.Clock.Now().Period()
+	if text, ok := r.Property("desc:" + period); ok {
+		return text
+	}
+	if r.Outdoors() {
+		return periodSky[period]
+	}
+	return ""
+}
+
+func showTime(p *Player, args []string) {
+	if p.Universe.Clock == nil {
+		p.WriteString("Time stands still.\n")
+		return
+	}
+	p.WriteString("It is " + p.Universe.Clock.Now().String() + ".\n")
+}
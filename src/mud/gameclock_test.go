@@ -0,0 +1,47 @@
+package mud
+
+import "testing"
+
+func TestGameTime(t *testing.T) {
+	day := MinutesPerHour * HoursPerDay
+	when := GameTime(DaysPerSeason*day + 2*day + 18*MinutesPerHour + 5)
+	if s := when.String(); s != "6:05 pm on day 3 of summer, year 1" {
+		t.Errorf("unexpected time '%s'", s)
+	}
+	if when.Period() != Dusk {
+		t.Errorf("6 pm should be dusk, is %s", when.Period())
+	}
+	midnight := GameTime(4 * DaysPerSeason * day)
+	if midnight.ClockTime() != "12:00 am" || midnight.Year() != 2 ||
+		midnight.Season() != "spring" || midnight.Period() != Night {
+		t.Errorf("unexpected new year %s, %s", midnight, midnight.Period())
+	}
+}
+
+func TestDawnDoesNotWaitForBusyRooms(t *testing.T) {
+	u := testUniverse()
+	busy, field := quietRoom(u), quietRoom(u)
+	busy.id, field.id = 1, 2
+	for _, r := range([]*Room{busy, field}) {
+		r.SetProperty("outdoors", "yes")
+		u.Rooms[r.id] = r
+	}
+	for i := 0; i < cap(busy.stimuliBroadcast); i++ {
+		busy.stimuliBroadcast <- DaylightStimulus{period: Night}
+	}
+	c := newGameClock(u, 1)
+	c.now = GameTime(DawnHour * MinutesPerHour - 1)
+
+	c.Advance(1)
+	if c.Now().Period() != Dawn {
+		t.Fatalf("clock should have reached dawn, is %s", c.Now())
+	}
+	select {
+	case s := <-field.stimuliBroadcast:
+		if s.(DaylightStimulus).period != Dawn {
+			t.Errorf("field saw %s", s.(DaylightStimulus).period)
+		}
+	default:
+		t.Error("field should have seen the dawn")
+	}
+}
@@ -0,0 +1,704 @@
+package mud
+
+import ("errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time")
+
+func init() {
+	PersistentKeys["change"] = []string{ "id", "builder", "when",
+		"target", "field", "before", "after", "undone" }
+
+	GlobalCommands["undo"] = undo
+	GlobalCommands["changes"] = listChanges
+}
+
+/*
+ Change records one builder edit to the world: who made it and when,
+ the entity changed (as a DB name like room:12, or "world" for what
+ isn't in a zone), which field, and the values before and after.
+ Structural edits use the fields "created", "deleted", "link" and
+ "unlink".
+ */
+type Change struct {
+	ID int
+	Builder string
+	When time.Time
+	Target string
+	Field string
+	Before string
+	After string
+	Undone bool
+}
+
+func (c *Change) PersistentValues() map[string]interface{} {
+	vals := make(map[string]interface{})
+	if c.ID > 0 {
+		vals["id"] = strconv.Itoa(c.ID)
+	}
+	vals["builder"] = c.Builder
+	vals["when"] = c.When.Format(time.RFC3339)
+	vals["target"] = c.Target
+	vals["field"] = c.Field
+	vals["before"] = c.Before
+	vals["after"] = c.After
+	vals["undone"] = strconv.FormatBool(c.Undone)
+	return vals
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("[%d] %s %s %s %s: %q -> %q",
+		c.ID, c.When.Format("2006-01-02 15:04"), c.Builder, c.Target,
+		c.Field, abbreviate(c.Before), abbreviate(c.After))
+}
+
+func abbreviate(s string) string {
+	s = strings.Replace(s, "\n", " ", -1)
+	if len(s) > 30 {
+		return s[:27] + "..."
+	}
+	return s
+}
+
+/*
+ Journal is the persistent, ordered record of builder edits. Builders'
+ commands record and undo changes from their own goroutines, so lock
+ guards changes and whether each is undone.
+ */
+type Journal struct {
+	universe *Universe
+	lock sync.RWMutex
+	changes []*Change
+}
+
+// LoadJournal loads every recorded change, oldest first.
+func LoadJournal(u *Universe) *Journal {
+	j := &Journal{universe: u}
+	for _, idStr := range(u.Store.GlobalSetGet("changes")) {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			Log("[warn] strange changeId", idStr)
+			continue
+		}
+		vals := u.Store.LoadStructure(PersistentKeys["change"],
+			FieldJoin(":","change",idStr))
+		c := &Change{ID: id, Builder: stringVal(vals, "builder"),
+			Target: stringVal(vals, "target"), Field: stringVal(vals, "field"),
+			Before: stringVal(vals, "before"), After: stringVal(vals, "after"),
+			Undone: stringVal(vals, "undone") == "true"}
+		c.When, _ = time.Parse(time.RFC3339, stringVal(vals, "when"))
+		j.changes = append(j.changes, c)
+	}
+	sort.Sort(changesByID(j.changes))
+	u.Journal = j
+	return j
+}
+
+type changesByID []*Change
+
+func (c changesByID) Len() int { return len(c) }
+func (c changesByID) Less(i, j int) bool { return c[i].ID < c[j].ID }
+func (c changesByID) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+func (j *Journal) save(c *Change) {
+	outID := j.universe.Store.SaveStructure("change", c.PersistentValues())
+	if c.ID == 0 {
+		c.ID, _ = strconv.Atoi(outID)
+		j.universe.Store.AddToGlobalSet("changes", outID)
+	}
+}
+
+// RecordChange saves a change made by p. It does nothing without a journal.
+func RecordChange(p *Player, target string, field string, before string, after string) {
+	j := p.Universe.Journal
+	if j == nil {
+		return
+	}
+	c := &Change{Builder: p.name, When: time.Now(), Target: target,
+		Field: field, Before: before, After: after}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.save(c)
+	j.changes = append(j.changes, c)
+}
+
+// Changes returns copies of the changes for which keep is true, oldest first
+func (j *Journal) Changes(keep func(*Change) bool) []*Change {
+	j.lock.RLock()
+	defer j.lock.RUnlock()
+	kept := []*Change{}
+	for _, c := range(j.changes) {
+		if keep(c) {
+			copied := *c
+			kept = append(kept, &copied)
+		}
+	}
+	return kept
+}
+
+// Reversible is false for changes undo passes over, like deletions
+func (c *Change) Reversible() bool {
+	kind, _ := splitTarget(c.Target)
+	return c.Field != "deleted" && !(kind == "zone" && c.Field == "created")
+}
+
+/*
+ Undo reverts p's last n reversible changes which aren't yet undone,
+ newest first, stopping at the first which fails or which p may no
+ longer edit. It returns the changes undone.
+ */
+func (j *Journal) Undo(p *Player, n int) ([]*Change, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	undone := []*Change{}
+	for i := len(j.changes) - 1; i >= 0 && len(undone) < n; i-- {
+		c := j.changes[i]
+		if c.Builder != p.name || c.Undone || !c.Reversible() {
+			continue
+		}
+		if !c.editableBy(j.universe, p) {
+			return undone, fmt.Errorf("change %d: you can't build there any more", c.ID)
+		}
+		if err := c.revert(j.universe); err != nil {
+			return undone, fmt.Errorf("change %d: %s", c.ID, err)
+		}
+		c.Undone = true
+		j.save(c)
+		undone = append(undone, c)
+	}
+	return undone, nil
+}
+
+func splitTarget(target string) (string, int) {
+	parts := strings.SplitN(target, ":", 2)
+	if len(parts) != 2 {
+		return target, 0
+	}
+	id, _ := strconv.Atoi(parts[1])
+	return parts[0], id
+}
+
+// editableBy is whether p may still edit what c changed
+func (c *Change) editableBy(u *Universe, p *Player) bool {
+	kind, id := splitTarget(c.Target)
+	switch kind {
+	case "room":
+		r, ok := u.Rooms[id]
+		return !ok || r.CanEdit(p)
+	case "zone":
+		z, ok := u.Zones[id]
+		return !ok || z.CanEdit(p)
+	case "world":
+		return p.IsBuilder()
+	case "roomConnect":
+		for _, r := range(c.linkedRooms(u)) {
+			if !r.CanEdit(p) { return false }
+		}
+		return p.IsBuilder()
+	}
+	found, ok := findObject(u, c.Target)
+	return !ok || found.room.CanEdit(p)
+}
+
+// linkedRooms are the rooms still in the world which a link change joined
+func (c *Change) linkedRooms(u *Universe) []*Room {
+	vals := parsePropertyStrings(strings.Split(c.Before + "\n" + c.After, "\n"))
+	rooms := []*Room{}
+	for _, key := range([]string{"roomAId", "roomBId"}) {
+		id, _ := strconv.Atoi(vals[key])
+		if r, ok := u.Rooms[id]; ok { rooms = append(rooms, r) }
+	}
+	return rooms
+}
+
+/*
+ current is the value now held by the field c changed, so that undo
+ can tell whether someone has changed it since. ok is false for
+ structural changes, and fields which can't be found.
+ */
+func (c *Change) current(u *Universe) (value string, ok bool) {
+	kind, id := splitTarget(c.Target)
+	field := strings.SplitN(c.Field, ":", 2)
+	switch kind {
+	case "room":
+		r, found := u.Rooms[id]
+		if !found {
+			return "", false
+		}
+		switch field[0] {
+		case "text":
+			return r.text, true
+		case "property":
+			return r.properties[field[1]], true
+		case "flag":
+			return flagValue(r.HasFlag(field[1])), true
+		case "extra":
+			text, _ := r.ExtraDescription(field[1])
+			return text, true
+		case "resets":
+			return strings.Join(resetStrings(r.resets), "\n"), true
+		case "zone":
+			return zoneValue(r.zone), true
+		}
+	case "zone":
+		z, found := u.Zones[id]
+		if !found {
+			return "", false
+		}
+		switch field[0] {
+		case "name":
+			return z.name, true
+		case "property":
+			return z.properties[field[1]], true
+		case "levels":
+			min, max := z.LevelRange()
+			return fmt.Sprintf("%d %d", min, max), true
+		case "reset":
+			return z.ResetPolicy(), true
+		case "owner":
+			return flagValue(z.owners[field[1]]), true
+		case "weather":
+			if u.Weather != nil {
+				return u.Weather.In(z), true
+			}
+		}
+	case "world":
+		if field[0] == "weather" && u.Weather != nil {
+			return u.Weather.In(nil), true
+		}
+	case "roomConnect":
+		// revert finds whether the link is still there itself
+	default:
+		if field[0] != "longDescription" {
+			return "", false
+		}
+		if found, ok := findObject(u, c.Target); ok {
+			if described, ok := found.Target.(LongDescriber); ok {
+				return described.LongDescription(), true
+			}
+		}
+	}
+	return "", false
+}
+
+func (c *Change) revert(u *Universe) error {
+	if now, ok := c.current(u); ok && now != c.After {
+		return errors.New("it has been changed since")
+	}
+	kind, id := splitTarget(c.Target)
+	field := strings.SplitN(c.Field, ":", 2)
+	switch kind {
+	case "room":
+		r, ok := u.Rooms[id]
+		if !ok {
+			return errors.New("the room no longer exists")
+		}
+		switch field[0] {
+		case "text":
+			r.SetText(c.Before)
+		case "property":
+			r.SetProperty(field[1], c.Before)
+		case "flag":
+			r.SetFlag(field[1], c.Before == "on")
+		case "extra":
+			r.SetExtraDescription(field[1], c.Before)
+		case "resets":
+			r.resets = nil
+			if c.Before != "" {
+				r.resets = parseResetStrings(strings.Split(c.Before, "\n"))
+			}
+		case "zone":
+			zoneID, _ := strconv.Atoi(c.Before)
+			r.zone = u.Zones[zoneID]
+		case "created":
+			if len(r.players) > 0 {
+				return errors.New("someone is in the room")
+			}
+			DeleteRoom(r)
+		default:
+			return errors.New("can't undo " + c.Field)
+		}
+	case "zone":
+		z, ok := u.Zones[id]
+		if !ok {
+			return errors.New("the zone no longer exists")
+		}
+		switch field[0] {
+		case "name":
+			z.SetName(c.Before)
+		case "property":
+			z.SetProperty(field[1], c.Before)
+		case "levels":
+			var min, max int
+			fmt.Sscan(c.Before, &min, &max)
+			z.SetLevelRange(min, max)
+		case "reset":
+			z.SetResetPolicy(c.Before)
+		case "owner":
+			if c.Before == "on" {
+				z.AddOwner(field[1])
+			} else {
+				z.RemoveOwner(field[1])
+			}
+		case "weather":
+			return revertWeather(u, z, c.Before)
+		default:
+			return errors.New("can't undo " + c.Field)
+		}
+	case "world":
+		if field[0] != "weather" {
+			return errors.New("can't undo " + c.Field)
+		}
+		return revertWeather(u, nil, c.Before)
+	case "roomConnect":
+		switch field[0] {
+		case "link":
+			rc := findConnection(u, id)
+			if rc == nil {
+				return errors.New("the link is already gone")
+			}
+			Disconnect(rc)
+		case "unlink":
+			return relink(u, c.Before)
+		default:
+			return errors.New("can't undo " + c.Field)
+		}
+	default:
+		found, ok := findObject(u, c.Target)
+		if !ok {
+			return errors.New("can't find " + c.Target)
+		}
+		switch field[0] {
+		case "created":
+			found.Exec()
+		case "longDescription":
+			editable, ok := found.Target.(LongDescriptionSetter)
+			if !ok {
+				return errors.New("can't undo " + c.Field)
+			}
+			editable.SetLongDescription(c.Before)
+		default:
+			return errors.New("can't undo " + c.Field)
+		}
+	}
+	return nil
+}
+
+func revertWeather(u *Universe, z *Zone, kind string) error {
+	if u.Weather == nil {
+		return errors.New("there is no weather")
+	}
+	u.Weather.Set(z, kind)
+	return nil
+}
+
+/*
+ findObject finds the object saved as name, wherever in the world it
+ is, as the VanishAction which would take it away.
+ */
+func findObject(u *Universe, name string) (VanishAction, bool) {
+	for _, r := range(u.Rooms) {
+		if found, ok := findObjectIn(r.PhysicalObjects(), nil, r, name); ok {
+			return found, true
+		}
+		for _, p := range(r.players) {
+			objs := append(p.Inventory(), p.Equipped()...)
+			if found, ok := findObjectIn(objs, p, r, name); ok {
+				return found, true
+			}
+		}
+	}
+	return VanishAction{}, false
+}
+
+func findObjectIn(objs []PhysicalObject, holder interface{}, r *Room, name string) (VanishAction, bool) {
+	for _, o := range(objs) {
+		if persister, ok := o.(Persister); ok && persister.DBFullName() == name {
+			return VanishAction{Target: o, Holder: holder, room: r}, true
+		}
+		if c, ok := o.(Container); ok {
+			if found, ok := findObjectIn(c.Contents(), c, r, name); ok {
+				return found, true
+			}
+		}
+	}
+	return VanishAction{}, false
+}
+
+func findConnection(u *Universe, id int) RoomConnection {
+	for _, r := range(u.Rooms) {
+		for _, exit := range(r.exits) {
+			if exit.exit.ID() == id { return exit.exit }
+		}
+	}
+	return nil
+}
+
+/*
+ describeConnection records what relink needs to restore a link: the
+ values it is saved with, a "key=value" line each, so that its kind,
+ door and exit options come back too.
+ */
+func describeConnection(rc RoomConnection) string {
+	props := make(map[string]string)
+	for key, value := range(rc.PersistentValues()) {
+		switch v := value.(type) {
+		case string:
+			props[key] = v
+		case []string:
+			props[key] = strings.Join(v, ",")
+		}
+	}
+	delete(props, "id")
+	return strings.Join(propertyStrings(props), "\n")
+}
+
+// relink restores an unlinked connection as describeConnection recorded it
+func relink(u *Universe, description string) error {
+	vals := make(Pvals)
+	for key, value := range(parsePropertyStrings(strings.Split(description, "\n"))) {
+		vals[key] = value
+	}
+	if len(vals) == 0 {
+		return errors.New("the link wasn't recorded")
+	}
+	if names := stringVal(vals, "discoveredBy"); names != "" {
+		vals["discoveredBy"] = strings.Split(names, ",")
+	}
+	kindLoader, ok := RoomConnKinds[stringVal(vals, "kind")]
+	if !ok {
+		return errors.New("the link's kind is unknown")
+	}
+	aID, _ := strconv.Atoi(stringVal(vals, "roomAId"))
+	bID, _ := strconv.Atoi(stringVal(vals, "roomBId"))
+	if u.Rooms[aID] == nil || u.Rooms[bID] == nil {
+		return errors.New("the linked rooms no longer exist")
+	}
+	connectValues(u, kindLoader, vals)
+	return nil
+}
+
+func flagValue(on bool) string {
+	if on {
+		return "on"
+	}
+	return ""
+}
+
+func zoneValue(z *Zone) string {
+	if z == nil {
+		return ""
+	}
+	return strconv.Itoa(z.id)
+}
+
+// The Edit functions make builder changes, recording them in the journal
+
+func EditRoomText(p *Player, r *Room, text string) {
+	RecordChange(p, r.DBFullName(), "text", r.text, text)
+	r.SetText(text)
+}
+
+func EditRoomProperty(p *Player, r *Room, key string, value string) {
+	RecordChange(p, r.DBFullName(), "property:" + key, r.properties[key], value)
+	r.SetProperty(key, value)
+}
+
+func EditRoomFlag(p *Player, r *Room, flag string, on bool) {
+	RecordChange(p, r.DBFullName(), "flag:" + flag,
+		flagValue(r.HasFlag(flag)), flagValue(on))
+	r.SetFlag(flag, on)
+}
+
+func EditExtraDescription(p *Player, r *Room, keyword string, text string) {
+	before, _ := r.ExtraDescription(keyword)
+	RecordChange(p, r.DBFullName(), "extra:" + strings.ToLower(keyword),
+		before, text)
+	r.SetExtraDescription(keyword, text)
+}
+
+func EditRoomResets(p *Player, r *Room, rules []ResetRule) {
+	RecordChange(p, r.DBFullName(), "resets",
+		strings.Join(resetStrings(r.resets), "\n"),
+		strings.Join(resetStrings(rules), "\n"))
+	r.resets = rules
+}
+
+func EditRoomZone(p *Player, r *Room, z *Zone) {
+	RecordChange(p, r.DBFullName(), "zone", zoneValue(r.zone), zoneValue(z))
+	r.zone = z
+}
+
+func EditZoneName(p *Player, z *Zone, name string) {
+	RecordChange(p, z.DBFullName(), "name", z.name, name)
+	z.SetName(name)
+}
+
+func EditZoneProperty(p *Player, z *Zone, key string, value string) {
+	RecordChange(p, z.DBFullName(), "property:" + key, z.properties[key], value)
+	z.SetProperty(key, value)
+}
+
+func EditZoneLevels(p *Player, z *Zone, min int, max int) {
+	oldMin, oldMax := z.LevelRange()
+	RecordChange(p, z.DBFullName(), "levels",
+		fmt.Sprintf("%d %d", oldMin, oldMax), fmt.Sprintf("%d %d", min, max))
+	z.SetLevelRange(min, max)
+}
+
+func EditZoneResetPolicy(p *Player, z *Zone, policy string) {
+	RecordChange(p, z.DBFullName(), "reset", z.ResetPolicy(), policy)
+	z.SetResetPolicy(policy)
+}
+
+func EditZoneOwner(p *Player, z *Zone, name string, owns bool) {
+	RecordChange(p, z.DBFullName(), "owner:" + name,
+		flagValue(z.owners[name]), flagValue(owns))
+	if owns {
+		z.AddOwner(name)
+	} else {
+		z.RemoveOwner(name)
+	}
+}
+
+// EditWeather sets the weather in z, or outside any zone if z is nil
+func EditWeather(p *Player, z *Zone, kind string) {
+	target := "world"
+	if z != nil {
+		target = z.DBFullName()
+	}
+	w := p.Universe.Weather
+	RecordChange(p, target, "weather", w.In(z), kind)
+	w.Set(z, kind)
+}
+
+// EditLongDescription changes o's long description, recording it if o is saved
+func EditLongDescription(p *Player, o LongDescriptionSetter, text string) {
+	if name := savedName(o); name != "" {
+		RecordChange(p, name, "longDescription", o.LongDescription(), text)
+	}
+	o.SetLongDescription(text)
+}
+
+// RecordObjectCreated notes that p made o, if o is saved
+func RecordObjectCreated(p *Player, o PhysicalObject) {
+	if name := savedName(o); name != "" {
+		RecordChange(p, name, "created", "", o.Description())
+	}
+}
+
+/*
+ savedName is o's DB name, saving it first if it has no ID yet, or ""
+ if o isn't saved at all.
+ */
+func savedName(o interface{}) string {
+	persister, ok := o.(Persister)
+	if !ok {
+		return ""
+	}
+	if unsaved(persister) {
+		persister.Save()
+	}
+	return persister.DBFullName()
+}
+
+// unsaved is true for persisters which have no ID yet
+func unsaved(persister Persister) bool {
+	return strings.HasSuffix(persister.DBFullName(), ":0")
+}
+
+// RecordCreated notes that p made r
+func RecordCreated(p *Player, r *Room) {
+	RecordChange(p, r.DBFullName(), "created", "", r.text)
+}
+
+// RecordDeleted notes that p deleted r, which can't be undone
+func RecordDeleted(p *Player, r *Room) {
+	RecordChange(p, r.DBFullName(), "deleted", r.text, "")
+}
+
+func RecordLinked(p *Player, rc RoomConnection) {
+	RecordChange(p, fmt.Sprintf("roomConnect:%d", rc.ID()), "link",
+		"", describeConnection(rc))
+}
+
+func RecordUnlinked(p *Player, rc RoomConnection) {
+	RecordChange(p, fmt.Sprintf("roomConnect:%d", rc.ID()), "unlink",
+		describeConnection(rc), "")
+}
+
+func undo(p *Player, args []string) {
+	if !p.IsBuilder() {
+		p.WriteString("Only builders may undo.\n")
+		return
+	}
+	if p.Universe.Journal == nil {
+		p.WriteString("Nothing is being recorded.\n")
+		return
+	}
+	n := 1
+	if len(args) == 1 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+			p.WriteString("Undo usage: undo [number of edits].\n")
+			return
+		}
+	}
+	undone, err := p.Universe.Journal.Undo(p, n)
+	for _, c := range(undone) {
+		p.WriteString("Undid " + c.String() + "\n")
+	}
+	if err != nil {
+		p.WriteString("Couldn't undo " + err.Error() + ".\n")
+	} else if len(undone) == 0 {
+		p.WriteString("You have nothing to undo.\n")
+	}
+}
+
+const changesUsage = `Changes usage:
+  changes                  the latest edits
+  changes room [room id]   edits to a room
+  changes builder [name]   edits by a builder
+`
+
+// How many changes the changes command shows
+const changesShown = 20
+
+func listChanges(p *Player, args []string) {
+	if !p.IsAdmin() {
+		p.WriteString("Only admins may review changes.\n")
+		return
+	}
+	if p.Universe.Journal == nil {
+		p.WriteString("Nothing is being recorded.\n")
+		return
+	}
+	keep := func(*Change) bool { return true }
+	switch {
+	case len(args) == 0:
+	case len(args) == 2 && args[0] == "room":
+		target := "room:" + args[1]
+		keep = func(c *Change) bool { return c.Target == target }
+	case len(args) == 2 && args[0] == "builder":
+		keep = func(c *Change) bool { return c.Builder == args[1] }
+	default:
+		p.WriteString(changesUsage)
+		return
+	}
+	changes := p.Universe.Journal.Changes(keep)
+	if len(changes) == 0 {
+		p.WriteString("No changes found.\n")
+		return
+	}
+	if len(changes) > changesShown {
+		changes = changes[len(changes) - changesShown:]
+	}
+	for _, c := range(changes) {
+		line := c.String()
+		if c.Undone { line += " (undone)" }
+		p.WriteString(line + "\n")
+	}
+}
@@ -0,0 +1,131 @@
+package mud
+
+import "testing"
+
+func TestChangeReversible(t *testing.T) {
+	text := &Change{Target: "room:3", Field: "text"}
+	deleted := &Change{Target: "room:3", Field: "deleted"}
+	zoneMade := &Change{Target: "zone:2", Field: "created"}
+	roomMade := &Change{Target: "room:4", Field: "created"}
+	if !text.Reversible() || !roomMade.Reversible() {
+		t.Errorf("edits and new rooms should be reversible")
+	}
+	if deleted.Reversible() || zoneMade.Reversible() {
+		t.Errorf("deletions and new zones should not be reversible")
+	}
+}
+
+func TestRevertRoomEdits(t *testing.T) {
+	u := &Universe{Rooms: map[int]*Room{}, Zones: map[int]*Zone{}}
+	r := testRoom(3)
+	r.text = "New"
+	r.flags = map[string]bool{FlagSafe: true}
+	r.properties = map[string]string{}
+	u.Rooms[3] = r
+	changes := []*Change{
+		{Target: "room:3", Field: "text", Before: "Old", After: "New"},
+		{Target: "room:3", Field: "flag:safe", Before: "", After: "on"},
+		{Target: "room:3", Field: "property:desc:night", Before: "Dark."},
+	}
+	for _, c := range(changes) {
+		if err := c.revert(u); err != nil {
+			t.Errorf("couldn't revert %s: %s", c, err)
+		}
+	}
+	if text, _ := r.Property("desc:night"); r.text != "Old" ||
+		r.HasFlag(FlagSafe) || text != "Dark." {
+		t.Errorf("room not reverted: %q, %v, %q", r.text, r.flags, text)
+	}
+	gone := &Change{Target: "room:9", Field: "text"}
+	if gone.revert(u) == nil {
+		t.Errorf("reverting a missing room should fail")
+	}
+}
+
+func TestRevertZoneEdits(t *testing.T) {
+	u := &Universe{Rooms: map[int]*Room{}, Zones: map[int]*Zone{}}
+	z := &Zone{id: 2, owners: map[string]bool{}, resetPolicy: ResetNever}
+	z.SetLevelRange(1, 5)
+	u.Zones[2] = z
+	changes := []*Change{
+		{Target: "zone:2", Field: "levels", Before: "3 8", After: "1 5"},
+		{Target: "zone:2", Field: "reset", Before: ResetAlways, After: ResetNever},
+		{Target: "zone:2", Field: "owner:Bob", Before: "on", After: ""},
+	}
+	for _, c := range(changes) {
+		if err := c.revert(u); err != nil {
+			t.Errorf("couldn't revert %s: %s", c, err)
+		}
+	}
+	if min, max := z.LevelRange(); min != 3 || max != 8 ||
+		z.ResetPolicy() != ResetAlways || !z.owners["Bob"] {
+		t.Errorf("zone not reverted: %d-%d, %s, %v", min, max, z.resetPolicy, z.owners)
+	}
+}
+
+func TestRevertRefusesLaterEdits(t *testing.T) {
+	u := &Universe{Rooms: map[int]*Room{}, Zones: map[int]*Zone{}}
+	r := testRoom(3)
+	r.text = "Newer"
+	u.Rooms[3] = r
+	c := &Change{Target: "room:3", Field: "text", Before: "Old", After: "New"}
+	if c.revert(u) == nil {
+		t.Errorf("reverting over a later edit should fail")
+	}
+	if r.text != "Newer" {
+		t.Errorf("later edit was lost: %q", r.text)
+	}
+}
+
+func TestChangeEditableBy(t *testing.T) {
+	u := &Universe{Rooms: map[int]*Room{}, Zones: map[int]*Zone{}}
+	z := &Zone{id: 2, owners: map[string]bool{"Bob": true}}
+	r := testRoom(3)
+	r.zone = z
+	u.Rooms[3], u.Zones[2] = r, z
+	alice := &Player{name: "Alice", builder: true}
+	roomEdit := &Change{Target: "room:3", Field: "text"}
+	zoneEdit := &Change{Target: "zone:2", Field: "name"}
+	link := &Change{Target: "roomConnect:5", Field: "unlink",
+		Before: "roomAId=3\nroomBId=4"}
+	for _, c := range([]*Change{roomEdit, zoneEdit, link}) {
+		if c.editableBy(u, alice) {
+			t.Errorf("%s shouldn't be editable outside the builder's zone", c.Target)
+		}
+	}
+	z.AddOwner("Alice")
+	for _, c := range([]*Change{roomEdit, zoneEdit, link}) {
+		if !c.editableBy(u, alice) {
+			t.Errorf("%s should be editable by the zone's owner", c.Target)
+		}
+	}
+}
+
+func TestRelinkRestoresDoor(t *testing.T) {
+	u := testUniverse()
+	a, b := testRoom(1), testRoom(2)
+	a.universe, b.universe = u, u
+	u.Rooms[1], u.Rooms[2] = a, b
+	door := WithExitOptions(DoorRoomConnectCreator("north", "south", "brass key", DoorLocked),
+		ExitOptions{Hidden: true, Condition: "carrying lamp"})
+	rc := ConnectWithConnCreator(door)(a, b)
+	rc.Options().Discover(&Player{name: "Alice"})
+	description := describeConnection(rc)
+	Disconnect(rc)
+
+	if err := relink(u, description); err != nil {
+		t.Fatalf("couldn't relink: %s", err)
+	}
+	if len(a.exits) != 1 {
+		t.Fatalf("room A has %d exits", len(a.exits))
+	}
+	restored, ok := a.exits[0].exit.(*DoorRoomConnection)
+	if !ok || restored.state != DoorLocked || restored.keyHandle != "brass key" ||
+		restored.AExitName() != "north" {
+		t.Errorf("door not restored: %#v", a.exits[0].exit)
+	}
+	if opts := a.exits[0].exit.Options(); !opts.Hidden ||
+		opts.Condition != "carrying lamp" || !opts.discoveredBy["Alice"] {
+		t.Errorf("exit options not restored: %#v", opts)
+	}
+}
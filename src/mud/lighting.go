@@ -0,0 +1,113 @@
+package mud
+
+import ("strconv"
+	"strings")
+
+func init() {
+	GlobalCommands["light"] = lightCommand(true)
+	GlobalCommands["extinguish"] = lightCommand(false)
+}
+
+// Light levels. Players can see anything but LightDark.
+const (
+	LightDark = iota
+	LightDim
+	LightBright
+)
+
+// Daylight outdoors at each time of day
+var daylight = map[string]int{
+	Dawn: LightDim, Day: LightBright, Dusk: LightDim, Night: LightDark,
+}
+
+/*
+ LightSource is a PhysicalObject which can give light, such as a lamp.
+ Objects which might be light sources but aren't (e.g. a simple
+ PhysicalObject not set up as one) return false from IsLightSource.
+ */
+type LightSource interface {
+	PhysicalObject
+	IsLightSource() bool
+	Lit() bool
+	SetLit(bool)
+}
+
+func isLitSource(o interface{}) bool {
+	light, ok := o.(LightSource)
+	return ok && light.IsLightSource() && light.Lit()
+}
+
+/*
+ LightLevel is how well players can see in r. Outdoors it follows the
+ time of day, never falling below the room's "light" property;
+ indoors the property decides, and rooms without one are bright. A
+ lit light source in the room, or carried by anyone there, keeps it
+ from being dark.
+ */
+func (r *Room) LightLevel() int {
+	level := LightBright
+	if r.Outdoors() && r.universe != nil && r.universe.Clock != nil {
+		level = daylight[r.universe.Clock.Now().Period()]
+	}
+	if prop, ok := r.Property("light"); ok {
+		if ambient, err := strconv.Atoi(prop); err == nil {
+			if !r.Outdoors() || ambient > level {
+				level = ambient
+			}
+		}
+	}
+	if level == LightDark && r.hasLitSource() {
+		level = LightDim
+	}
+	return level
+}
+
+func (r *Room) IsDark() bool { return r.LightLevel() == LightDark }
+
+func (r *Room) hasLitSource() bool {
+	for _, o := range(r.PhysicalObjects()) {
+		if isLitSource(o) { return true }
+	}
+	for _, p := range(r.players) {
+		for _, o := range(append(p.Inventory(), p.Equipped()...)) {
+			if isLitSource(o) { return true }
+		}
+	}
+	return false
+}
+
+func lightCommand(lit bool) Command {
+	verb := "Light"
+	if !lit { verb = "Extinguish" }
+	return func(p *Player, args []string) {
+		if len(args) == 0 {
+			p.WriteString(verb + " usage: " + strings.ToLower(verb) +
+				" [object].\n")
+			return
+		}
+		target, ok := p.PerceiveList(LookContext)[strings.ToLower(args[0])]
+		if !ok {
+			p.WriteString("You don't see that here.\n")
+			return
+		}
+		light, isLight := target.(LightSource)
+		switch {
+		case !isLight || !light.IsLightSource():
+			p.WriteString("That won't give any light.\n")
+		case lit && light.Lit():
+			p.WriteString("It is already lit.\n")
+		case !lit && !light.Lit():
+			p.WriteString("It isn't lit.\n")
+		default:
+			light.SetLit(lit)
+			if lit {
+				p.WriteString("You light the " + args[0] + ".\n")
+				if vanish, gone := Wear(target, p, p.room); gone {
+					p.room.Act(vanish)
+				}
+			} else {
+				p.WriteString("You put out the " + args[0] + ".\n")
+			}
+		}
+	}
+}
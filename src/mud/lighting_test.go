@@ -0,0 +1,63 @@
+package mud
+
+import "testing"
+
+type testLamp struct {
+	testThing
+	lit bool
+}
+
+func (l *testLamp) IsLightSource() bool { return true }
+func (l *testLamp) Lit() bool { return l.lit }
+func (l *testLamp) SetLit(lit bool) { l.lit = lit }
+
+func TestDarknessHidesObjects(t *testing.T) {
+	r := quietRoom(testUniverse())
+	r.SetProperty("light", "0")
+	r.AddChild(&testThing{handle: "barrel"})
+	p, socket := testPlayer(r)
+	r.players[p.id] = p
+	lamp := &testLamp{testThing: testThing{handle: "lamp", carryable: true}}
+	p.Add(lamp)
+
+	if !r.IsDark() {
+		t.Fatal("a room with light 0 and no lit lamp should be dark")
+	}
+	if _, seen := p.PerceiveList(LookContext)["barrel"]; seen {
+		t.Error("the barrel shouldn't be seen in the dark")
+	}
+	if _, held := p.PerceiveList(LookContext)["lamp"]; !held {
+		t.Error("the player should still find what they carry")
+	}
+	Look(p, []string{})
+	if socket.written != "It is pitch black.\n" {
+		t.Errorf("looking in the dark gave %q", socket.written)
+	}
+
+	lamp.SetLit(true)
+	if r.LightLevel() != LightDim {
+		t.Errorf("a lit lamp should make the room dim, is %d", r.LightLevel())
+	}
+	if _, seen := p.PerceiveList(LookContext)["barrel"]; !seen {
+		t.Error("the barrel should be seen by lamplight")
+	}
+}
+
+func TestSayInTheDark(t *testing.T) {
+	r := quietRoom(testUniverse())
+	alice, _ := testPlayer(r)
+	bob, _ := testPlayer(r)
+	bob.id, bob.name = 2, "Bob"
+	say := TalkerSayStimulus{talker: bob, text: "hello"}
+
+	if heard := say.Description(alice); heard != "Bob said \"hello\".\n" {
+		t.Errorf("in the light Alice heard %q", heard)
+	}
+	r.SetProperty("light", "0")
+	if heard := say.Description(alice); heard != "Someone says \"hello\".\n" {
+		t.Errorf("in the dark Alice heard %q", heard)
+	}
+	if heard := say.Description(bob); heard != "You say \"hello\"\n" {
+		t.Errorf("the speaker heard %q", heard)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ Package mudtest has what tests of the mud packages need to run
+ without a Redis server.
+ */
+package mudtest
+
+import ("redis"
+	"sort"
+	"strconv"
+	"sync")
+
+/*
+ memoryClient is a redis.Client keeping its keys in memory, with just
+ the commands mud.TinyDB uses.
+ */
+type memoryClient struct {
+	redis.Client
+	lock sync.Mutex
+	strings map[string][]byte
+	sets map[string]map[string]bool
+}
+
+func (m *memoryClient) Get(key string) ([]byte, redis.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.strings[key], nil
+}
+
+func (m *memoryClient) Type(key string) (redis.KeyType, redis.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.strings[key]; ok {
+		return redis.RT_STRING, nil
+	}
+	if _, ok := m.sets[key]; ok {
+		return redis.RT_SET, nil
+	}
+	return redis.RT_NONE, nil
+}
+
+func (m *memoryClient) Set(key string, value []byte) redis.Error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.strings[key] = value
+	return nil
+}
+
+func (m *memoryClient) Exists(key string) (bool, redis.Error) {
+	kind, _ := m.Type(key)
+	return kind != redis.RT_NONE, nil
+}
+
+func (m *memoryClient) Incr(key string) (int64, redis.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	n, _ := strconv.Atoi(string(m.strings[key]))
+	m.strings[key] = []byte(strconv.Itoa(n + 1))
+	return int64(n + 1), nil
+}
+
+func (m *memoryClient) Del(key string) (bool, redis.Error) {
+	exists, _ := m.Exists(key)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.strings, key)
+	delete(m.sets, key)
+	return exists, nil
+}
+
+func (m *memoryClient) Sadd(key string, member []byte) (bool, redis.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.sets[key] == nil {
+		m.sets[key] = make(map[string]bool)
+	}
+	added := !m.sets[key][string(member)]
+	m.sets[key][string(member)] = true
+	return added, nil
+}
+
+func (m *memoryClient) Srem(key string, member []byte) (bool, redis.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	removed := m.sets[key][string(member)]
+	delete(m.sets[key], string(member))
+	if len(m.sets[key]) == 0 {
+		delete(m.sets, key)
+	}
+	return removed, nil
+}
+
+// Smembers returns members in order, so tests can compare them
+func (m *memoryClient) Smembers(key string) ([][]byte, redis.Error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	names := []string{}
+	for member := range(m.sets[key]) { names = append(names, member) }
+	sort.Strings(names)
+	members := [][]byte{}
+	for _, name := range(names) { members = append(members, []byte(name)) }
+	return members, nil
+}
+
+func (m *memoryClient) Flushdb() redis.Error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.strings = make(map[string][]byte)
+	m.sets = make(map[string]map[string]bool)
+	return nil
+}
+
+/*
+ NewMemoryClient is an empty in-memory store, for a universe made by
+ mud.NewUniverseWithClient.
+ */
+func NewMemoryClient() redis.Client {
+	m := new(memoryClient)
+	m.Flushdb()
+	return m
+}
@@ -0,0 +1,359 @@
+package mud
+
+import ("fmt"
+	"sort"
+	"strconv"
+	"strings")
+
+func init() {
+	GlobalCommands["dig"] = dig
+	GlobalCommands["link"] = link
+	GlobalCommands["unlink"] = unlink
+	GlobalCommands["rdelete"] = roomDelete
+	GlobalCommands["goto"] = gotoRoom
+	GlobalCommands["create"] = create
+	GlobalCommands["redit"] = roomEdit
+	GlobalCommands["oedit"] = objectEdit
+}
+
+// Prototype makes a new object of some kind, ready to place in a room
+type Prototype func(u *Universe) PhysicalObject
+
+// Objects builders can create by name, registered by the game
+var Prototypes = make(map[string]Prototype)
+
+// LongDescriptionSetter is a LongDescriber builders can edit
+type LongDescriptionSetter interface {
+	LongDescriber
+	SetLongDescription(string)
+}
+
+func requireBuilder(p *Player) bool {
+	if !p.room.CanEdit(p) {
+		p.WriteString("You can't build here.\n")
+		return false
+	}
+	return true
+}
+
+// splitExitArgs splits "exit [= return exit]"
+func splitExitArgs(text string) (string, string) {
+	parts := strings.SplitN(text, "=", 2)
+	exitName := strings.TrimSpace(parts[0])
+	if len(parts) == 1 {
+		return exitName, ""
+	}
+	return exitName, strings.TrimSpace(parts[1])
+}
+
+func returnExitFor(p *Player, exitName string, returnName string) (string, bool) {
+	if returnName != "" {
+		return returnName, true
+	}
+	if opposite, ok := OppositeExit(exitName); ok {
+		return opposite, true
+	}
+	p.WriteString("No return exit known for '" + exitName +
+		"', use [exit] = [return exit].\n")
+	return "", false
+}
+
+func hasExit(r *Room, exitName string) bool {
+	found := false
+	r.WithExit(exitName, func(*RoomExitInfo) { found = true }, func() {})
+	return found
+}
+
+/*
+ dig makes a new room through an exit and takes the builder there. If
+ the exit leads by compass to a position which already has a room,
+ that room is linked instead, if the builder may edit it and it has
+ no return exit of that name already. The title is then unused.
+ */
+func dig(p *Player, args []string) {
+	if !requireBuilder(p) {
+		return
+	}
+	if len(args) < 1 {
+		p.WriteString("Dig usage: dig [exit] [room title], " +
+			"or dig [exit] = [return exit].\n")
+		return
+	}
+	exitName, returnName := args[0], ""
+	title := strings.Join(args[1:], " ")
+	if strings.Contains(title, "=") {
+		exitName, returnName = splitExitArgs(strings.Join(args, " "))
+		title = ""
+	}
+	returnName, ok := returnExitFor(p, exitName, returnName)
+	if !ok {
+		return
+	}
+	here := p.room
+	if hasExit(here, exitName) {
+		p.WriteString("That exit already exists.\n")
+		return
+	}
+
+	var target *Room
+	if c, hasCoords := here.Coords(); hasCoords {
+		if offset, isCompass := CompassOffset(exitName); isCompass {
+			target = RoomAt(p.Universe, c.Add(offset))
+		}
+	}
+	if target == nil {
+		if title == "" { title = "A new room." }
+		target = NewRoom(p.Universe, 0, title)
+		target.zone = here.zone
+		RecordCreated(p, target)
+		p.WriteString(fmt.Sprintf("Dug room %d.\n", target.id))
+	} else if !target.CanEdit(p) {
+		p.WriteString(fmt.Sprintf("Room %d is already %s of here, " +
+			"and you can't build there.\n", target.id, exitName))
+		return
+	} else if hasExit(target, returnName) {
+		p.WriteString(fmt.Sprintf("Room %d is already %s of here, " +
+			"and already has a %s exit.\n", target.id, exitName, returnName))
+		return
+	} else {
+		p.WriteString(fmt.Sprintf("Linked to room %d, already %s of here.\n",
+			target.id, exitName))
+		if title != "" {
+			p.WriteString("It keeps its own title.\n")
+		}
+	}
+	rc := ConnectWithConnCreator(
+		SimpleRoomConnectCreator(exitName, returnName))(here, target)
+	rc.Save()
+	RecordLinked(p, rc)
+	if ok, reason := target.CanEnter(p); !ok {
+		p.WriteString(reason)
+		return
+	}
+	placePlayer(target, p, "", "")
+	Look(p, []string{})
+}
+
+// Kinds of connection link can make
+var linkKinds = map[string]func(a string, b string) RoomConnCreator{
+	"simple": SimpleRoomConnectCreator,
+	"door": func(a string, b string) RoomConnCreator {
+		return DoorRoomConnectCreator(a, b, "", DoorClosed)
+	},
+	"oneway": func(a string, b string) RoomConnCreator {
+		return WithExitOptions(SimpleRoomConnectCreator(a, b),
+			ExitOptions{OneWay: true})
+	},
+	"hidden": func(a string, b string) RoomConnCreator {
+		return WithExitOptions(SimpleRoomConnectCreator(a, b),
+			ExitOptions{Hidden: true})
+	},
+}
+
+const linkUsage = `Link usage: link [room id] [exit] [= return exit] [as kind]
+Kinds are simple (the default), door, oneway and hidden.
+`
+
+func link(p *Player, args []string) {
+	if !requireBuilder(p) {
+		return
+	}
+	if len(args) < 2 {
+		p.WriteString(linkUsage)
+		return
+	}
+	target := targetRoom(p, args[:1])
+	if target == nil {
+		p.WriteString("No room " + args[0] + ".\n")
+		return
+	}
+	if !target.CanEdit(p) {
+		p.WriteString("You can't build there.\n")
+		return
+	}
+	spec := strings.Join(args[1:], " ")
+	kind := "simple"
+	if i := strings.LastIndex(spec, " as "); i >= 0 {
+		spec, kind = spec[:i], strings.TrimSpace(spec[i+4:])
+	}
+	makeConn, ok := linkKinds[kind]
+	if !ok {
+		p.WriteString(linkUsage)
+		return
+	}
+	exitName, returnName := splitExitArgs(spec)
+	returnName, ok = returnExitFor(p, exitName, returnName)
+	if !ok {
+		return
+	}
+	if hasExit(p.room, exitName) || (kind != "oneway" && hasExit(target, returnName)) {
+		p.WriteString("One of those exits already exists.\n")
+		return
+	}
+	rc := ConnectWithConnCreator(makeConn(exitName, returnName))(p.room, target)
+	rc.Save()
+	RecordLinked(p, rc)
+	p.WriteString(fmt.Sprintf("Linked %s to room %d.\n", exitName, target.id))
+}
+
+// Disconnect removes a connection from both its rooms and the database
+func Disconnect(rc RoomConnection) {
+	for _, r := range([]*Room{rc.RoomA(), rc.RoomB()}) {
+		exits := []RoomExitInfo{}
+		for _, exit := range(r.exits) {
+			if exit.exit != rc { exits = append(exits, exit) }
+		}
+		r.exits = exits
+	}
+	u := rc.RoomA().universe
+	u.Remove(rc)
+	id := strconv.Itoa(rc.ID())
+	u.Store.RemoveFromGlobalSet("roomConnects", id)
+	keys := append([]string{}, PersistentKeys["roomConnect"]...)
+	if kind, ok := rc.PersistentValues()["kind"].(string); ok {
+		keys = append(keys, PersistentKeys[FieldJoin(":","roomConnect",kind)]...)
+	}
+	u.Store.DeleteStructure("roomConnect", id, keys)
+}
+
+func unlink(p *Player, args []string) {
+	if !requireBuilder(p) {
+		return
+	}
+	exitName := strings.Join(args, " ")
+	p.room.WithExit(exitName, func(exit *RoomExitInfo) {
+		if !exit.OtherSide().CanEdit(p) {
+			p.WriteString("You can't build there.\n")
+			return
+		}
+		RecordUnlinked(p, exit.exit)
+		Disconnect(exit.exit)
+		p.WriteString("Unlinked " + exitName + ".\n")
+	}, func() {
+		p.WriteString("Unlink usage: unlink [exit].\n")
+	})
+}
+
+/*
+ DeleteRoom unlinks and forgets an empty room, and deletes its record.
+ Its contents go with it. Anything still holding the room once its
+ loops stop finds Broadcast and Act do nothing.
+ */
+func DeleteRoom(r *Room) {
+	for len(r.exits) > 0 {
+		Disconnect(r.exits[0].exit)
+	}
+	// One-way exits into r are only listed in the other room, and
+	// Disconnect changes that list, so find them all first
+	into := []RoomConnection{}
+	for _, other := range(r.universe.Rooms) {
+		for _, exit := range(other.exits) {
+			if exit.OtherSide() == r { into = append(into, exit.exit) }
+		}
+	}
+	for _, rc := range(into) {
+		Disconnect(rc)
+	}
+	for _, o := range(r.PhysicalObjects()) {
+		Destroy(r.universe, o)
+	}
+	delete(r.universe.Rooms, r.id)
+	r.universe.Remove(r)
+	id := strconv.Itoa(r.id)
+	r.universe.Store.RemoveFromGlobalSet("rooms", id)
+	r.universe.Store.DeleteStructure("room", id, PersistentKeys["room"])
+	close(r.done)
+}
+
+func roomDelete(p *Player, args []string) {
+	if !requireBuilder(p) {
+		return
+	}
+	target := targetRoom(p, args)
+	switch {
+	case target == nil:
+		p.WriteString("Rdelete usage: rdelete [room id].\n")
+	case !target.CanEdit(p):
+		p.WriteString("You can't build there.\n")
+	case target == p.room || len(target.players) > 0:
+		p.WriteString("You can't delete a room with people in it.\n")
+	case target == p.Universe.StartRoom:
+		p.WriteString("The starting room can't be deleted.\n")
+	default:
+		RecordDeleted(p, target)
+		DeleteRoom(target)
+		p.WriteString(fmt.Sprintf("Deleted room %d.\n", target.id))
+	}
+}
+
+func gotoRoom(p *Player, args []string) {
+	if !p.IsBuilder() {
+		p.WriteString("Only builders may goto.\n")
+		return
+	}
+	target := targetRoom(p, args)
+	if target == nil {
+		p.WriteString("Goto usage: goto [room id].\n")
+		return
+	}
+	if !target.CanEdit(p) {
+		p.WriteString("You can't build there.\n")
+		return
+	}
+	if ok, reason := target.CanEnter(p); !ok {
+		p.WriteString(reason)
+		return
+	}
+	placePlayer(target, p, "appears in a flash", "vanishes in a flash")
+	Look(p, []string{})
+}
+
+func create(p *Player, args []string) {
+	if !requireBuilder(p) {
+		return
+	}
+	o, ok := MakePrototype(p.Universe, strings.Join(args, " "))
+	if !ok {
+		names := []string{}
+		for name := range(Prototypes) { names = append(names, name) }
+		sort.Strings(names)
+		p.WriteString("Create usage: create [prototype]. Prototypes are: " +
+			strings.Join(names, ", ") + ".\n")
+		return
+	}
+	o.SetRoom(p.room)
+	p.room.AddChild(o)
+	RecordObjectCreated(p, o)
+	p.WriteString("Created " + o.Description() + ".\n")
+}
+
+func roomEdit(p *Player, args []string) {
+	if !requireBuilder(p) {
+		return
+	}
+	r := p.room
+	p.Edit(r.text, func(text string) { EditRoomText(p, r, text) })
+}
+
+func objectEdit(p *Player, args []string) {
+	if !requireBuilder(p) {
+		return
+	}
+	if len(args) == 0 {
+		p.WriteString("Oedit usage: oedit [object].\n")
+		return
+	}
+	target, ok := p.PerceiveList(LookContext)[strings.ToLower(args[0])]
+	if !ok {
+		p.WriteString("You don't see that here.\n")
+		return
+	}
+	editable, ok := target.(LongDescriptionSetter)
+	if !ok {
+		p.WriteString("That can't be described at length.\n")
+		return
+	}
+	p.Edit(editable.LongDescription(), func(text string) {
+		EditLongDescription(p, editable, text)
+	})
+}
@@ -0,0 +1,111 @@
+package mud
+
+import ("strconv"
+	"strings"
+	"testing")
+
+func TestDeleteRoom(t *testing.T) {
+	u := testUniverse()
+	a, b, c := NewRoom(u, 0, "A"), NewRoom(u, 0, "B"), NewRoom(u, 0, "C")
+	u.StartRoom = a
+	for _, name := range([]string{"chute", "slide"}) {
+		ConnectWithConnCreator(linkKinds["oneway"](name, ""))(a, b)
+	}
+	bToC := ConnectEastWest(b, c)
+	bToC.Save()
+	b.Save()
+	ball := &testThing{testObject: testObject{name: "a ball"}, handle: "ball"}
+	ball.SetRoom(b)
+	b.AddChild(ball)
+
+	BuilderNames["Alice"] = true
+	defer delete(BuilderNames, "Alice")
+	p, socket := testPlayer(c)
+	roomDelete(p, []string{strconv.Itoa(a.id)})
+	if socket.written != "The starting room can't be deleted.\n" || u.Rooms[a.id] == nil {
+		t.Errorf("start room shouldn't be deleted, got %q", socket.written)
+	}
+
+	DeleteRoom(b)
+	if len(a.exits) != 0 || len(c.exits) != 0 {
+		t.Errorf("exits into b should be gone, a has %d and c has %d",
+			len(a.exits), len(c.exits))
+	}
+	if ball.Room() != nil || len(b.PhysicalObjects()) != 0 {
+		t.Error("b's contents should go with it")
+	}
+	if u.Rooms[b.id] != nil {
+		t.Error("b should be forgotten")
+	}
+	select {
+	case <- b.done:
+	default:
+		t.Error("b's goroutines should be stopped")
+	}
+	roomVals := u.Store.LoadStructure(PersistentKeys["room"], b.DBFullName())
+	connVals := u.Store.LoadStructure(PersistentKeys["roomConnect"],
+		FieldJoin(":", "roomConnect", strconv.Itoa(bToC.ID())))
+	if stringVal(roomVals, "text") != "" || stringVal(connVals, "aExitName") != "" {
+		t.Errorf("b's records should be deleted, got %v and %v", roomVals, connVals)
+	}
+	// More than the queues hold, which would block if b's loops were waited on
+	for i := 0; i < 20; i++ {
+		b.Act(VanishAction{Target: ball})
+		b.Broadcast(VanishStimulus{obj: ball})
+	}
+}
+
+func TestGotoChecksDestination(t *testing.T) {
+	u := testUniverse()
+	here, owned, private := NewRoom(u, 0, "Here"), NewRoom(u, 0, "Owned"), NewRoom(u, 0, "Private")
+	owned.zone = &Zone{owners: map[string]bool{"Bob": true}}
+	private.SetFlag(FlagPrivate, true)
+	for i := 0; i < PrivateRoomLimit; i++ {
+		private.players[100 + i] = &Player{}
+	}
+
+	BuilderNames["Alice"] = true
+	defer delete(BuilderNames, "Alice")
+	p, socket := testPlayer(here)
+	gotoRoom(p, []string{strconv.Itoa(owned.id)})
+	if p.room != here || socket.written != "You can't build there.\n" {
+		t.Errorf("goto into another's zone should fail, got %q", socket.written)
+	}
+	socket.written = ""
+	gotoRoom(p, []string{strconv.Itoa(private.id)})
+	if p.room != here || socket.written != "That room is private, and already occupied.\n" {
+		t.Errorf("goto into a full private room should fail, got %q", socket.written)
+	}
+}
+
+func TestDigIntoExistingRoom(t *testing.T) {
+	u := testUniverse()
+	here, east, beyond := quietRoom(u), quietRoom(u), quietRoom(u)
+	for i, r := range([]*Room{here, east, beyond}) {
+		r.id, r.text = i + 1, "Room " + strconv.Itoa(i + 1)
+		u.Rooms[r.id] = r
+	}
+	here.SetCoords(Coord{0, 0, 0})
+	east.SetCoords(Coord{1, 0, 0})
+	ConnectEastWest(beyond, east)
+
+	BuilderNames["Alice"] = true
+	defer delete(BuilderNames, "Alice")
+	p, socket := testPlayer(here)
+	dig(p, []string{"east", "A", "garden"})
+	want := "Room " + strconv.Itoa(east.id) + " is already east of here, " +
+		"and already has a west exit.\n"
+	if socket.written != want || len(here.exits) != 0 || p.room != here {
+		t.Errorf("dig shouldn't add a second west exit, got %q", socket.written)
+	}
+
+	beyond.exits, east.exits = nil, nil
+	socket.written = ""
+	dig(p, []string{"east", "A", "garden"})
+	if len(here.exits) != 1 || here.exits[0].OtherSide() != east || east.text != "Room 2" {
+		t.Errorf("dig should link to the room east of here, got %q", socket.written)
+	}
+	if want := "It keeps its own title.\n"; !strings.Contains(socket.written, want) {
+		t.Errorf("dig should say the title went unused, got %q", socket.written)
+	}
+}
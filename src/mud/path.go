@@ -0,0 +1,265 @@
+package mud
+
+import ("fmt"
+	"strconv"
+	"strings"
+	"unicode")
+
+func init() {
+	GlobalCommands["path"] = showPath
+	GlobalCommands["travel"] = travel
+	GlobalCommands["speedwalk"] = speedwalk
+
+	PlayerPerceptions["move"] = doesPerceiveMove
+
+	for abbrev, exitName := range(speedwalkAbbrevs) {
+		if _, taken := GlobalCommands[abbrev]; !taken {
+			GlobalCommands[abbrev] = goAlias(exitName)
+		}
+	}
+}
+
+var speedwalkAbbrevs = map[string]string{
+	"n": "north", "s": "south", "e": "east", "w": "west",
+	"u": "up", "d": "down",
+	"ne": "northeast", "nw": "northwest",
+	"se": "southeast", "sw": "southwest",
+}
+
+func goAlias(exitName string) Command {
+	return func(p *Player, args []string) {
+		goExit(p, []string{exitName})
+	}
+}
+
+/*
+ FindPath returns the names of the exits leading from one room to
+ another by the fewest steps, using only exits visible to viewer
+ (nil for the exits anyone can see). ok is false if there is no path.
+ The search is breadth first: coordinates can't guide it, since
+ portals and named exits may join rooms however far apart.
+ */
+func FindPath(from *Room, to *Room, viewer *Player) (exits []string, ok bool) {
+	return findPath(from, to, func(exit *RoomExitInfo) bool {
+		return exit.VisibleTo(viewer)
+	})
+}
+
+// findPath is FindPath using only the exits usable allows
+func findPath(from *Room, to *Room, usable func(*RoomExitInfo) bool) (exits []string, ok bool) {
+	type step struct {
+		prev *Room
+		exit string
+	}
+	steps := map[*Room]step{from: {}}
+	queue := []*Room{from}
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+		if r == to {
+			for r := to; r != from; r = steps[r].prev {
+				exits = append([]string{steps[r].exit}, exits...)
+			}
+			return exits, true
+		}
+		for i := range(r.exits) {
+			exit := &r.exits[i]
+			if !usable(exit) {
+				continue
+			}
+			next := exit.OtherSide()
+			if _, seen := steps[next]; seen {
+				continue
+			}
+			steps[next] = step{prev: r, exit: exit.Name()}
+			queue = append(queue, next)
+		}
+	}
+	return nil, false
+}
+
+/*
+ OpenToNPCs is false for exits NPCs can't take: hidden ones, doors
+ which aren't open, and those with a condition, which only players
+ can meet.
+ */
+func (r *RoomExitInfo) OpenToNPCs() bool {
+	if !r.VisibleTo(nil) || r.exit.Options().Condition != "" {
+		return false
+	}
+	door, isDoor := r.exit.(*DoorRoomConnection)
+	return !isDoor || door.DoorState() == DoorOpen
+}
+
+// NextStep is the first exit on the path between two rooms, for NPCs
+// navigating one step at a time. It only uses exits OpenToNPCs.
+func NextStep(from *Room, to *Room) (string, bool) {
+	exits, ok := findPath(from, to, (*RoomExitInfo).OpenToNPCs)
+	if !ok || len(exits) == 0 {
+		return "", false
+	}
+	return exits[0], true
+}
+
+// The most times a speedwalk may repeat one direction
+const maxSpeedwalkCount = 100
+
+/*
+ ParseSpeedwalk expands speedwalk text such as "3n2e" or "2n, se"
+ into exit names. A count, at most maxSpeedwalkCount, applies to the
+ direction following it.
+ */
+func ParseSpeedwalk(text string) ([]string, error) {
+	exits := []string{}
+	runes := []rune(strings.ToLower(text))
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) || runes[i] == ',' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && unicode.IsDigit(runes[i]) { i++ }
+		count := 1
+		if i > start {
+			count, _ = strconv.Atoi(string(runes[start:i]))
+		}
+		// Diagonals take precedence, so "sw" is southwest
+		exitName := ""
+		for _, width := range([]int{2, 1}) {
+			if exitName == "" && i+width <= len(runes) {
+				if name, ok := speedwalkAbbrevs[string(runes[i:i+width])]; ok {
+					exitName = name
+					i += width
+				}
+			}
+		}
+		if exitName == "" {
+			return nil, fmt.Errorf("unrecognized direction at '%s'",
+				string(runes[start:]))
+		}
+		if count < 1 || count > maxSpeedwalkCount {
+			return nil, fmt.Errorf("bad count at '%s'", string(runes[start:]))
+		}
+		for n := 0; n < count; n++ {
+			exits = append(exits, exitName)
+		}
+	}
+	return exits, nil
+}
+
+// CompressPath writes exits in speedwalk form, e.g. "3e n", where
+// every exit has an abbreviation; otherwise as a comma list.
+func CompressPath(exits []string) string {
+	abbrevs := make(map[string]string)
+	for abbrev, exitName := range(speedwalkAbbrevs) {
+		abbrevs[exitName] = abbrev
+	}
+	parts := []string{}
+	for i := 0; i < len(exits); {
+		abbrev, ok := abbrevs[exits[i]]
+		if !ok {
+			return strings.Join(exits, ", ")
+		}
+		n := 1
+		for i+n < len(exits) && exits[i+n] == exits[i] { n++ }
+		if n > 1 {
+			parts = append(parts, strconv.Itoa(n) + abbrev)
+		} else {
+			parts = append(parts, abbrev)
+		}
+		i += n
+	}
+	return strings.Join(parts, " ")
+}
+
+func targetRoom(p *Player, args []string) *Room {
+	if len(args) != 1 {
+		return nil
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil
+	}
+	return p.Universe.Rooms[id]
+}
+
+func showPath(p *Player, args []string) {
+	target := targetRoom(p, args)
+	if target == nil {
+		p.WriteString("Path usage: path [room id].\n")
+		return
+	}
+	exits, ok := FindPath(p.room, target, p)
+	switch {
+	case !ok:
+		p.WriteString("You don't know a way there.\n")
+	case len(exits) == 0:
+		p.WriteString("You are already there.\n")
+	default:
+		p.WriteString(fmt.Sprintf("Path to room %d (%d steps): %s\n",
+			target.id, len(exits), CompressPath(exits)))
+	}
+}
+
+func queueGoCommands(p *Player, exits []string) {
+	cmds := make([]string, len(exits))
+	for i, exitName := range(exits) {
+		cmds[i] = "go " + exitName
+	}
+	p.QueueCommands(cmds...)
+}
+
+func travel(p *Player, args []string) {
+	target := targetRoom(p, args)
+	if target == nil {
+		p.WriteString("Travel usage: travel [room id].\n")
+		return
+	}
+	if exits, ok := FindPath(p.room, target, p); ok {
+		queueGoCommands(p, exits)
+	} else {
+		p.WriteString("You don't know a way there.\n")
+	}
+}
+
+func speedwalk(p *Player, args []string) {
+	if len(args) == 0 {
+		p.WriteString("Speedwalk usage: speedwalk [directions]. Ex. speedwalk 3n2e\n")
+		return
+	}
+	exits, err := ParseSpeedwalk(strings.Join(args, " "))
+	if err != nil {
+		p.WriteString("Speedwalk: " + err.Error() + ".\n")
+		return
+	}
+	queueGoCommands(p, exits)
+}
+
+/*
+ ObjectMoveStimulus announces a non-player object, such as an NPC,
+ leaving or arriving in a room.
+ */
+type ObjectMoveStimulus struct {
+	Stimulus
+	obj PhysicalObject
+	message string
+}
+
+func (s ObjectMoveStimulus) StimType() string { return "move" }
+func (s ObjectMoveStimulus) Description(p Perceiver) string {
+	return objectName(s.obj) + " " + s.message + ".\n"
+}
+func (s ObjectMoveStimulus) Object() PhysicalObject { return s.obj }
+
+func doesPerceiveMove(p Player, s Stimulus) bool { return true }
+
+// MoveObject moves o through exit to the room on the other side.
+func MoveObject(o PhysicalObject, exit *RoomExitInfo) {
+	from, to := o.Room(), exit.OtherSide()
+	if from != nil {
+		from.Broadcast(ObjectMoveStimulus{obj: o, message: exit.DepartMessage()})
+		from.RemoveChild(o)
+	}
+	to.AddChild(o)
+	to.Broadcast(ObjectMoveStimulus{obj: o, message: exit.ArriveMessage()})
+}
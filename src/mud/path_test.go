@@ -0,0 +1,84 @@
+package mud
+
+import ("reflect"
+	"strings"
+	"testing")
+
+func TestParseSpeedwalk(t *testing.T) {
+	exits, err := ParseSpeedwalk("3n2e sw, u")
+	expected := []string{"north", "north", "north", "east", "east",
+		"southwest", "up"}
+	if err != nil || !reflect.DeepEqual(exits, expected) {
+		t.Errorf("ParseSpeedwalk gave %v, %v", exits, err)
+	}
+	if _, err := ParseSpeedwalk("2x"); err == nil {
+		t.Errorf("unknown directions should be an error")
+	}
+	if _, err := ParseSpeedwalk("999999999n"); err == nil ||
+		!strings.Contains(err.Error(), "bad count") {
+		t.Errorf("huge counts should be a bad count, got %v", err)
+	}
+}
+
+func TestCompressPath(t *testing.T) {
+	if s := CompressPath([]string{"east", "east", "north"}); s != "2e n" {
+		t.Errorf("unexpected compressed path '%s'", s)
+	}
+	if s := CompressPath([]string{"east", "climb ladder"}); s != "east, climb ladder" {
+		t.Errorf("unexpected uncompressed path '%s'", s)
+	}
+}
+
+func TestFindPath(t *testing.T) {
+	a, b, c, d := testRoom(1), testRoom(2), testRoom(3), testRoom(4)
+	testConnect(a, b, EastWestRoomConnection)
+	testConnect(b, c, NorthSouthRoomConnection)
+	testConnect(a, d, WithExitOptions(SimpleRoomConnectCreator("down", "up"),
+		ExitOptions{Hidden: true}))
+	testConnect(d, c, EastWestRoomConnection)
+
+	exits, ok := FindPath(a, c, nil)
+	if !ok || !reflect.DeepEqual(exits, []string{"east", "north"}) {
+		t.Errorf("unexpected path %v", exits)
+	}
+	if _, ok := FindPath(c, testRoom(5), nil); ok {
+		t.Errorf("unconnected room should have no path")
+	}
+
+	// A portal between distant rooms is still a single step
+	far, portal := testRoom(6), testRoom(7)
+	far.SetCoords(Coord{10, 0, 0})
+	portal.SetCoords(Coord{0, 0, 0})
+	a.SetCoords(Coord{9, 0, 0})
+	testConnect(a, far, EastWestRoomConnection)
+	testConnect(c, portal, SimpleRoomConnectCreator("enter portal", "leave"))
+	testConnect(portal, far, SimpleRoomConnectCreator("enter portal", "leave"))
+	exits, ok = FindPath(c, far, nil)
+	if !ok || len(exits) != 2 {
+		t.Errorf("expected the path through the portal, got %v", exits)
+	}
+	if _, ok := FindPath(c, testRoom(8), nil); ok {
+		t.Errorf("unconnected room should have no path")
+	}
+}
+
+func TestNextStepAvoidsShutExits(t *testing.T) {
+	a, b, c, d := testRoom(1), testRoom(2), testRoom(3), testRoom(4)
+	testConnect(a, b, DoorRoomConnectCreator("east", "west", "", DoorClosed))
+	testConnect(a, c, WithExitOptions(NorthSouthRoomConnection,
+		ExitOptions{Condition: "money 10"}))
+	testConnect(a, d, UpDownRoomConnection)
+	testConnect(d, b, EastWestRoomConnection)
+	testConnect(d, c, NorthSouthRoomConnection)
+
+	if exit, ok := NextStep(a, b); !ok || exit != "up" {
+		t.Errorf("NPCs should go around the closed door, got %q", exit)
+	}
+	if exit, ok := NextStep(a, c); !ok || exit != "up" {
+		t.Errorf("NPCs should go around the guarded exit, got %q", exit)
+	}
+	a.exits[0].exit.(*DoorRoomConnection).state = DoorOpen
+	if exit, ok := NextStep(a, b); !ok || exit != "east" {
+		t.Errorf("NPCs should use the open door, got %q", exit)
+	}
+}
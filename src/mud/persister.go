@@ -6,9 +6,9 @@ import ("time"
 
 type Loader func(universe *Universe, id int) interface{}
 
-var Loaders map[string]Loader
+var Loaders = make(map[string]Loader)
 // Map of [type name] -> [field names that persist]
-var PersistentKeys map[string][]string
+var PersistentKeys = make(map[string][]string)
 
 func ifPersists(o interface{}, ifTrue func(Persister)) {
 	oAsPersister, persists := o.(Persister)
@@ -17,9 +17,6 @@ func ifPersists(o interface{}, ifTrue func(Persister)) {
 }
 
 func init() {
-	PersistentKeys = make(map[string][]string)
-	Loaders = make(map[string]Loader)
-
 	containerHelper := new(FlexObjHandlerPair) 
 	containerHelper.Add = func(fc *FlexContainer, o interface{}) {
 		ifPersists(o, func(Persister) {
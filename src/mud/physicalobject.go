@@ -30,9 +30,43 @@ func init() {
 	FlexObjHandlers["PhysicalObjects"] = *containerHelper
 }
 
+/*
+ Destroyable is implemented by objects with something to tidy away
+ when destroyed, such as a record the universe keeps saving.
+ */
+type Destroyable interface {
+	Destroyed()
+}
+
+/*
+ Destroy takes o out of the world for good: out of its room and the
+ universe, so it is neither pinged nor saved. Whoever holds it removes
+ it first.
+ */
+func Destroy(u *Universe, o PhysicalObject) {
+	if r := o.Room(); r != nil {
+		r.RemoveChild(o)
+	}
+	o.SetRoom(nil)
+	u.Remove(o)
+	if d, ok := o.(Destroyable); ok {
+		d.Destroyed()
+	}
+}
+
+/*
+ VanishAction takes Target out of the world (see Destroy), seen to go
+ with Message if there is one. Holder is the player or container
+ holding it, if any; otherwise it goes from its room. Carried objects
+ don't keep track of their room, so it is seen to go from a player
+ holder's room, and the room must be given for a container holder.
+ */
 type VanishAction struct {
 	InterObjectAction
 	Target PhysicalObject
+	Holder interface{}
+	Message string
+	room *Room
 }
 
 func (p VanishAction) Targets() []PhysicalObject {
@@ -42,8 +76,30 @@ func (p VanishAction) Targets() []PhysicalObject {
 }
 func (p VanishAction) Source() PhysicalObject { return p.Target }
 func (p VanishAction) Exec() {
-	plant := p.Target
-	room := p.Target.Room()
-	room.RemoveChild(plant)
+	room := p.room
+	switch holder := p.Holder.(type) {
+	case *Player:
+		holder.inventory.Remove(p.Target)
+		if slot, ok := holder.slotOf(p.Target); ok {
+			delete(holder.equipment, slot)
+		}
+		if room == nil {
+			room = holder.room
+		}
+	case Container:
+		holder.RemoveContent(p.Target)
+	case nil:
+		if room == nil {
+			room = p.Target.Room()
+		}
+	}
+	if room == nil {
+		return
+	}
+	room.RemoveChild(p.Target)
+	if p.Message != "" {
+		room.Broadcast(VanishStimulus{obj: p.Target, message: p.Message})
+	}
+	Destroy(room.universe, p.Target)
 	Log(p, "vanishing")
-}
\ No newline at end of file
+}
@@ -5,14 +5,21 @@ import ("strconv"
 	"fmt")
 
 func init() {
-	PersistentKeys["player"] = []string{ "id", "name", "money" }
+	PersistentKeys["player"] = []string{ "id", "name", "money", "builder",
+		"strength", "equipment", "health", "buffs" }
 }
 
 type Currency int
 type PerceiveTest func(p Player, s Stimulus) bool
 
-var PlayerPerceptions map[string]PerceiveTest
+var PlayerPerceptions = make(map[string]PerceiveTest)
+// Names of players who are always builders, e.g. from the command line
+var BuilderNames = make(map[string]bool)
+// Names of players who administer the game, from the command line
+var AdminNames = make(map[string]bool)
+// The bulk a player of average strength can carry
 const MAX_INVENTORY = 10
+const DefaultStrength = 10
 
 type playerPersister struct {
 	Persister
@@ -29,10 +36,18 @@ type Player struct {
 	room *Room
 	name string
 	inventory *FlexContainer
+	equipment map[string]PhysicalObject
 	money Currency
+	builder bool
+	strength int
+	health int
+	buffs []Buff
 	Universe *Universe
 	commandBuf chan string
 	stimuli chan Stimulus
+	queuedCommands []string
+	// set by Edit, so this command's prompt is left to the editor
+	editing bool
 	quitting chan bool
 	commandDone chan bool
 }
@@ -67,6 +82,9 @@ func NewPlayer(u *Universe, name string) *Player {
 	p.commandDone = make(chan bool, 1)
 	p.stimuli = make(chan Stimulus, 5)
 	p.inventory = NewFlexContainer("PhysicalObjects")
+	p.equipment = make(map[string]PhysicalObject)
+	p.strength = DefaultStrength
+	p.health = MaxHealth
 	p.saveLoader = new(playerPersister)
 	p.saveLoader.player = p
 	p.Universe = u
@@ -83,6 +101,19 @@ func LoadPlayer(u *Universe, name string) *Player {
 	p.name = vals["name"].(string)
 	money, _ := strconv.Atoi(vals["money"].(string))
 	p.money = Currency(money)
+	p.builder, _ = strconv.ParseBool(stringVal(vals, "builder"))
+	if strength, err := strconv.Atoi(stringVal(vals, "strength")); err == nil {
+		p.strength = strength
+	}
+	if health, err := strconv.Atoi(stringVal(vals, "health")); err == nil {
+		p.health = health
+	}
+	if buffs, ok := vals["buffs"].([]string); ok {
+		p.buffs = parseBuffStrings(buffs)
+	}
+	if equipment, ok := vals["equipment"].([]string); ok {
+		p.equipment = loadEquipment(u, equipment)
+	}
 	return p
 }
 
@@ -93,6 +124,11 @@ func (p *playerPersister) PersistentValues() map[string]interface{} {
 	}
 	vals["name"] = p.player.name
 	vals["money"] = strconv.Itoa(int(p.player.money))
+	vals["builder"] = strconv.FormatBool(p.player.builder)
+	vals["strength"] = strconv.Itoa(p.player.strength)
+	vals["equipment"] = equipmentStrings(p.player.equipment)
+	vals["health"] = strconv.Itoa(p.player.Health())
+	vals["buffs"] = buffStrings(p.player.Buffs())
 	return vals
 }
 
@@ -109,6 +145,13 @@ func (p *playerPersister) Save() string {
 
 var colorMap map[string]string
 
+/*
+ Save writes p's record. Players aren't saved with the universe's
+ persisters, so this is done as their equipment changes and as they
+ quit.
+ */
+func (p *Player) Save() { p.saveLoader.Save() }
+
 func (p Player) ID() int { return p.id }
 func (p Player) Name() string { return p.name }
 func (p Player) StimuliChannel() chan Stimulus { return p.stimuli }
@@ -124,8 +167,8 @@ func init() {
 	GlobalCommands["quit"] = quit
 	GlobalCommands["make"] = mudMake
 	GlobalCommands["profit"] = profit
+	GlobalCommands["promote"] = promote
 	
-	PlayerPerceptions = make(map[string]PerceiveTest)
 	PlayerPerceptions["enter"] = doesPerceiveEnter
 	PlayerPerceptions["exit"] = doesPerceiveExit
 	PlayerPerceptions["say"] = doesPerceiveSay
@@ -143,14 +186,31 @@ func RemovePlayerFromRoom(r *Room, p *Player) {
 }
 
 func PlacePlayerInRoom(r *Room, p *Player) {
+	placePlayer(r, p, "", "")
+}
+
+/*
+ MovePlayer moves p through exit, telling the room left behind and
+ the room entered how p travelled (and if they struggled under their
+ load).
+ */
+func MovePlayer(p *Player, exit *RoomExitInfo) {
+	arrive, depart := exit.ArriveMessage(), exit.DepartMessage()
+	if p.Encumbrance() == Burdened {
+		arrive += ", staggering under a heavy load"
+		depart += ", staggering under a heavy load"
+	}
+	placePlayer(exit.OtherSide(), p, arrive, depart)
+}
+
+func placePlayer(r *Room, p *Player, from string, to string) {
 	oldRoom := p.room
 	if oldRoom != nil {
-		oldRoom.stimuliBroadcast <- 
-			PlayerLeaveStimulus{player: p}
+		oldRoom.Broadcast(PlayerLeaveStimulus{player: p, to: to})
 		RemovePlayerFromRoom(oldRoom, p)
 	}
 	
-	r.stimuliBroadcast <- PlayerEnterStimulus{player: p}
+	r.Broadcast(PlayerEnterStimulus{player: p, from: from})
 	r.AddChild(p)
 	r.players[p.id] = p
 }
@@ -165,12 +225,16 @@ func (p Player) TextHandles() []string {
 	return []string{ strings.ToLower(p.name) }
 }
 
+// Add puts o in p's inventory, on a stack like it if p has one
 func (p *Player) Add(o interface{}) {
+	if po, ok := o.(PhysicalObject); ok && MergeStack(p.Universe, p.Inventory(), po) {
+		return
+	}
 	p.inventory.Add(o)
 }
 
 func (p *Player) TakeObject(o *PhysicalObject, r *Room) bool {
-	if len(p.Inventory()) < MAX_INVENTORY {
+	if p.CanCarry(*o) {
 		r.RemoveChild(*o)
 		p.Add(*o)
 		return true
@@ -182,36 +246,76 @@ func (p *Player) TakeObject(o *PhysicalObject, r *Room) bool {
 func (p *Player) DropObject(o *PhysicalObject, r *Room) bool {
 	Log("Dropping", o, "to", r)
 	p.inventory.Remove(*o)
-	r.AddChild(*o)
+	if !MergeStack(p.Universe, r.PhysicalObjects(), *o) {
+		r.AddChild(*o)
+	}
 
 	return true
 }
 
 func (p *Player) ExecCommandLoop() {
 	for {
-		nextCommand := <-p.commandBuf
-		nextCommandSplit := SplitCommandString(nextCommand)
-		if nextCommandSplit != nil && len(nextCommandSplit) > 0 {
-			nextCommandRoot := nextCommandSplit[0]
-			nextCommandArgs := nextCommandSplit[1:]
-			if c, ok := GlobalCommands[nextCommandRoot]; ok {
-				c(p, nextCommandArgs)
-			} else if c, ok := p.Room().Commands()[nextCommandRoot]; ok{
-				c(p, nextCommandArgs)
-			} else {
-				p.WriteString("Command '" + nextCommandRoot + "' not recognized.\n")
-			}
+		p.execCommand(<-p.commandBuf)
+		for len(p.queuedCommands) > 0 {
+			queued := p.queuedCommands[0]
+			p.queuedCommands = p.queuedCommands[1:]
+			p.execCommand(queued)
+		}
+		// An editor writes its own prompt
+		if p.editing {
+			p.editing = false
+		} else {
+			p.WriteString("> ")
 		}
-		p.WriteString("> ")
 		p.commandDone <- true
 	}
 }
 
+/*
+ QueueCommands adds commands to run, in order, once the current
+ command finishes. A failed movement clears the queue.
+ */
+func (p *Player) QueueCommands(cmds ...string) {
+	p.queuedCommands = append(p.queuedCommands, cmds...)
+}
+
+func (p *Player) execCommand(nextCommand string) {
+	nextCommandSplit := SplitCommandString(nextCommand)
+	if nextCommandSplit != nil && len(nextCommandSplit) > 0 {
+		nextCommandRoot := nextCommandSplit[0]
+		nextCommandArgs := nextCommandSplit[1:]
+		if message, forbidden := p.room.Forbids(nextCommandRoot); forbidden {
+			p.WriteString(message)
+			p.queuedCommands = nil
+			return
+		}
+		if c, ok := GlobalCommands[nextCommandRoot]; ok {
+			c(p, nextCommandArgs)
+		} else if c, ok := p.Room().Commands()[nextCommandRoot]; ok{
+			c(p, nextCommandArgs)
+		} else if c, ok := SocialCommands[nextCommandRoot]; ok {
+			c(p, nextCommandArgs)
+		} else {
+			// Exit names can be used as commands, e.g. "climb ladder"
+			p.Room().WithVisibleExit(p, strings.Join(nextCommandSplit, " "),
+				func(*RoomExitInfo) {
+					goExit(p, nextCommandSplit)
+				}, func() {
+					p.WriteString("Command '" + nextCommandRoot + "' not recognized.\n")
+				})
+		}
+	}
+}
+
+// Look describes the room, or with arguments the thing they name
 func Look(p *Player, args []string) {
 	room := p.room
-	if len(args) > 1 {
-		Log("Too many args")
-		p.WriteString("Too many args")
+	if len(args) > 1 && args[0] == "in" {
+		LookIn(p, strings.Join(args[1:], " "))
+	} else if len(args) > 0 {
+		LookAt(p, strings.Join(args, " "))
+	} else if room.IsDark() {
+		p.WriteString("It is pitch black.\n")
 	} else {
 		p.WriteString(room.Describe(p) + "\n")
 	}
@@ -235,15 +339,16 @@ func who(p *Player, args []string) {
 func say(p *Player, args []string) {
 	room := p.room
 	sayStim := TalkerSayStimulus{talker: p, text: strings.Join(args," ")}
-	room.stimuliBroadcast <- sayStim
+	room.Broadcast(sayStim)
 }
 
 func take(p *Player, args []string) {
 	room := p.room
-	if len(args) > 0 {
+	if what, from, ok := splitOn(args, "from"); ok {
+		room.Act(TakeFromAction{player: p, what: what, from: from})
+	} else if len(args) > 0 {
 		target := strings.ToLower(args[0])
-		room.interactionQueue <-
-			PlayerTakeAction{ player: p, userTargetIdent: target }
+		room.Act(PlayerTakeAction{ player: p, userTargetIdent: target })
 	} else {
 		p.WriteString("Take objects by typing 'take [object name]'.\n")
 	}
@@ -251,12 +356,12 @@ func take(p *Player, args []string) {
 
 func drop(p *Player, args []string) {
 	room := p.room
+	n, args := splitCount(args)
 	if len(args) > 0 {
 		target := strings.ToLower(args[0])
-		room.interactionQueue <-
-			PlayerDropAction{ player: p, userTargetIdent: target }
+		room.Act(PlayerDropAction{ player: p, userTargetIdent: target, count: n })
 	} else {
-		p.WriteString("Drop objects by typing 'drop [object name]'.\n")
+		p.WriteString("Drop objects by typing 'drop [number] [object name]'.\n")
 	}
 }
 
@@ -271,18 +376,47 @@ func profit(p *Player, args []string) {
 	}
 }
 
+func promote(p *Player, args []string) {
+	if !p.IsAdmin() {
+		p.WriteString("Only admins may promote players.\n")
+		return
+	}
+	if len(args) != 1 {
+		p.WriteString("Promote usage: promote [player name].\n")
+		return
+	}
+	for _, other := range(p.Universe.Players) {
+		if strings.ToLower(other.name) == strings.ToLower(args[0]) {
+			other.builder = true
+			other.saveLoader.Save()
+			p.WriteString(other.name + " is now a builder.\n")
+			other.WriteString("You are now a builder.\n")
+			return
+		}
+	}
+	p.WriteString("No player " + args[0] + " online.\n")
+}
+
 func inv(p *Player, args []string) {
 	p.WriteString(Divider())
 	p.WriteString("Inventory: \n")
 	for _, obj := range p.Inventory() {
 		if obj != nil {
-			p.WriteString(obj.Description())
+			p.WriteString(StackDescription(obj))
 			p.WriteString("\n")
 		}
 	}
 	p.WriteString("You have ")
 	p.WriteString(strconv.Itoa(int(p.money)))
 	p.WriteString(" bitbux.\n")
+	p.WriteString(fmt.Sprintf("Carrying %d/%d pounds, bulk %d/%d (%s).\n",
+		p.CarriedWeight(), p.MaxWeight(), p.Load(), p.MaxLoad(), p.Encumbrance()))
+	p.WriteString(fmt.Sprintf("Health %d/%d.\n", p.Health(), MaxHealth))
+	if buffs := p.Buffs(); len(buffs) > 0 {
+		names := []string{}
+		for _, b := range(buffs) { names = append(names, b.Name) }
+		p.WriteString("You are " + strings.Join(names, ", ") + ".\n")
+	}
 	p.WriteString(Divider())
 }
 
@@ -292,12 +426,29 @@ func goExit(p *Player, args []string) {
 		p.WriteString("Go usage: go [exit name]. Ex. go north")
 		return 
 	}
+	exitName := strings.Join(args, " ")
 
-	room.WithExit(args[0], func(foundExit *RoomExitInfo) {
-		PlacePlayerInRoom(foundExit.OtherSide(), p)
+	room.WithVisibleExit(p, exitName, func(foundExit *RoomExitInfo) {
+		if p.Encumbrance() == Overloaded {
+			p.WriteString("You are carrying too much to move.\n")
+			p.queuedCommands = nil
+			return
+		}
+		if ok, reason := foundExit.exit.CanPass(p, foundExit.exitSide); !ok {
+			p.WriteString(reason)
+			p.queuedCommands = nil
+			return
+		}
+		if ok, reason := foundExit.OtherSide().CanEnter(p); !ok {
+			p.WriteString(reason)
+			p.queuedCommands = nil
+			return
+		}
+		MovePlayer(p, foundExit)
 		Look(p, []string{})
 	}, func() {
-		p.WriteString("No visible exit " + args[0] + ".\n")
+		p.WriteString("No visible exit " + exitName + ".\n")
+		p.queuedCommands = nil
 	})
 }
 
@@ -315,11 +466,12 @@ func (p *Player) ReadLoop(playerRemoveChan chan *Player) {
 		select {
 		case <-p.quitting:
 			Log("quitting in ReadLoop")
+			p.Save()
 			playerRemoveChan <- p
 			p.WriteString("Goodbye!")
 			p.Conn.Close()
 			return
-		case c := <- p.Conn.FromUser:
+		case c := <- p.Conn.InBand:
 			p.commandBuf <- c
 		}
 	}
@@ -365,6 +517,12 @@ func (p Player) PerceiveList(context PerceiveContext) PerceiveMap {
 
 	targetList = []PhysicalObject{}
 
+	// In the dark, only what the player holds can be found
+	if room.IsDark() {
+		people = map[int]*Player{}
+		roomObjects = []PhysicalObject{}
+	}
+
 	if(context == LookContext) {
 		targetList = append(targetList, PlayersAsPhysObjSlice(people)...)
 	}
@@ -374,6 +532,9 @@ func (p Player) PerceiveList(context PerceiveContext) PerceiveMap {
 	if(context == InvContext || context == LookContext) {
 		targetList = append(targetList, invObjects...)
 	}
+	if(context == LookContext) {
+		targetList = append(targetList, p.Equipped()...)
+	}
 
 	for _,target := range(targetList) {
 		Log(target)
@@ -387,6 +548,18 @@ func (p Player) PerceiveList(context PerceiveContext) PerceiveMap {
 	return physObjects
 }
 
+// IsBuilder is true for players allowed to change the world
+func (p *Player) IsBuilder() bool {
+	return p.builder || BuilderNames[p.name] || p.IsAdmin()
+}
+
+// IsAdmin is true for players who oversee the builders
+func (p *Player) IsAdmin() bool {
+	return AdminNames[p.name]
+}
+
+func (p *Player) SetBuilder(b bool) { p.builder = b }
+
 func (p *Player) Money() Currency {
 	return p.money
 }
@@ -395,7 +568,7 @@ func (p *Player) AdjustMoney(amount Currency) {
 }
 
 func (p *Player) ReceiveObject(o *PhysicalObject) bool {
-	if len(p.Inventory()) < MAX_INVENTORY {
+	if p.CanCarry(*o) {
 		p.Add(*o)
 		return true
 	}
@@ -0,0 +1,362 @@
+package mud
+
+import ("errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync")
+
+func init() {
+	GlobalCommands["rreset"] = roomResetCommand
+}
+
+/*
+ ResetRule keeps Count objects made by a Prototype in a room, without
+ making more than WorldMax of them in all (0 for no limit). Written as
+ "1 ball" or "1 puritan, max 1".
+ */
+type ResetRule struct {
+	Count int
+	Prototype string
+	WorldMax int
+}
+
+func (rule ResetRule) String() string {
+	text := fmt.Sprintf("%d %s", rule.Count, rule.Prototype)
+	if rule.WorldMax > 0 {
+		text += fmt.Sprintf(", max %d", rule.WorldMax)
+	}
+	return text
+}
+
+// ParseResetRule reads a rule written as ResetRule.String writes them
+func ParseResetRule(text string) (ResetRule, error) {
+	var rule ResetRule
+	parts := strings.SplitN(text, ",", 2)
+	words := strings.SplitN(strings.TrimSpace(parts[0]), " ", 2)
+	count, err := strconv.Atoi(words[0])
+	if err != nil || count < 1 || len(words) != 2 {
+		return rule, errors.New("resets are [count] [prototype][, max [number]]")
+	}
+	rule.Count, rule.Prototype = count, strings.TrimSpace(words[1])
+	if len(parts) == 2 {
+		max := strings.TrimSpace(parts[1])
+		if !strings.HasPrefix(max, "max ") {
+			return rule, errors.New("resets are [count] [prototype][, max [number]]")
+		}
+		if rule.WorldMax, err = strconv.Atoi(strings.TrimSpace(max[4:])); err != nil ||
+			rule.WorldMax < 1 {
+			return rule, errors.New("max should be a number above 0")
+		}
+	}
+	return rule, nil
+}
+
+/*
+ Needed is how many objects the rule should make, given how many are
+ in its room and in the world.
+ */
+func (rule ResetRule) Needed(inRoom int, inWorld int) int {
+	needed := rule.Count - inRoom
+	if rule.WorldMax > 0 && rule.WorldMax - inWorld < needed {
+		needed = rule.WorldMax - inWorld
+	}
+	if needed < 0 {
+		return 0
+	}
+	return needed
+}
+
+func (r *Room) Resets() []ResetRule { return r.resets }
+
+func (r *Room) SetResets(rules []ResetRule) { r.resets = rules }
+
+func resetStrings(rules []ResetRule) []string {
+	texts := []string{}
+	for _, rule := range(rules) { texts = append(texts, rule.String()) }
+	return texts
+}
+
+func parseResetStrings(texts []string) []ResetRule {
+	sort.Strings(texts)
+	rules := []ResetRule{}
+	for _, text := range(texts) {
+		if rule, err := ParseResetRule(text); err == nil {
+			rules = append(rules, rule)
+		} else {
+			Log("[warn] bad reset", text, err)
+		}
+	}
+	return rules
+}
+
+/*
+ Prototyped is implemented by objects which remember the name of the
+ Prototype which made them.
+ */
+type Prototyped interface {
+	PrototypeName() string
+	SetPrototypeName(string)
+}
+
+/*
+ MakePrototype makes an object with the named Prototype, marking it
+ with the name if it is Prototyped so resets can count it.
+ */
+func MakePrototype(u *Universe, name string) (PhysicalObject, bool) {
+	makeObject, ok := Prototypes[name]
+	if !ok {
+		return nil, false
+	}
+	o := makeObject(u)
+	if prototyped, ok := o.(Prototyped); ok {
+		prototyped.SetPrototypeName(name)
+	}
+	return o, true
+}
+
+/*
+ IsInstance is true if o was made by the named prototype, or answers
+ to its name (as objects made in Go, like seed.go's, do).
+ */
+func IsInstance(o PhysicalObject, prototype string) bool {
+	if prototyped, ok := o.(Prototyped); ok && prototyped.PrototypeName() != "" {
+		return prototyped.PrototypeName() == prototype
+	}
+	for _, handle := range(o.TextHandles()) {
+		if handle == prototype { return true }
+	}
+	return false
+}
+
+// countInstances counts each of a stack
+func countInstances(objects []PhysicalObject, prototype string) int {
+	n := 0
+	for _, o := range(objects) {
+		if IsInstance(o, prototype) { n += CountOf(o) }
+	}
+	return n
+}
+
+// countHeld also counts what containers among objects hold
+func countHeld(objects []PhysicalObject, prototype string) int {
+	n := countInstances(objects, prototype)
+	for _, o := range(objects) {
+		if c, ok := o.(Container); ok {
+			n += countHeld(c.Contents(), prototype)
+		}
+	}
+	return n
+}
+
+// CountInWorld counts instances in every room, inventory and container
+func CountInWorld(u *Universe, prototype string) int {
+	n := 0
+	for _, r := range(u.Rooms) {
+		n += countHeld(r.PhysicalObjects(), prototype)
+	}
+	for _, p := range(u.Players) {
+		n += countHeld(p.Inventory(), prototype)
+		n += countHeld(p.Equipped(), prototype)
+	}
+	return n
+}
+
+/*
+ ResetRoom makes what r's rules need, returning a note of each object
+ made. It changes r, so it should run in r's action queue, as
+ ResetAction does.
+ */
+func ResetRoom(r *Room) []string {
+	made := []string{}
+	for _, rule := range(r.resets) {
+		needed := rule.Needed(countInstances(r.PhysicalObjects(), rule.Prototype),
+			CountInWorld(r.universe, rule.Prototype))
+		for i := 0; i < needed; i++ {
+			o, ok := MakePrototype(r.universe, rule.Prototype)
+			if !ok {
+				Log("[reset] room", r.id, "has a reset for unknown prototype",
+					rule.Prototype)
+				break
+			}
+			o.SetRoom(r)
+			if !MergeStack(r.universe, r.PhysicalObjects(), o) {
+				r.AddChild(o)
+			}
+			made = append(made, fmt.Sprintf("room %d: %s", r.id, o.Description()))
+		}
+	}
+	return made
+}
+
+/*
+ ResetAction resets its room, sending the notes of what was made to
+ made, which needs space for them.
+ */
+type ResetAction struct {
+	InterObjectAction
+	room *Room
+	made chan []string
+}
+
+func (a ResetAction) Targets() []PhysicalObject { return []PhysicalObject{} }
+func (a ResetAction) Source() PhysicalObject { return nil }
+func (a ResetAction) Exec() { a.made <- ResetRoom(a.room) }
+
+// resetInQueue resets r in its action queue and waits until it's done
+func resetInQueue(r *Room) []string {
+	action := ResetAction{room: r, made: make(chan []string, 1)}
+	r.Act(action)
+	select {
+	case made := <- action.made:
+		return made
+	case <- r.done:
+		return nil
+	}
+}
+
+// resetting lets one reset run at a time, so WorldMax counts are right
+var resetting sync.Mutex
+
+func hasPlayers(rooms []*Room) bool {
+	for _, r := range(rooms) {
+		if len(r.players) > 0 { return true }
+	}
+	return false
+}
+
+/*
+ ResetRooms resets rooms which share a zone (or have none), following
+ the zone's reset policy unless forced. Rooms without a zone reset
+ when empty. Each room is reset in turn in its own action queue, and
+ what was made is written to the log.
+ */
+func ResetRooms(z *Zone, rooms []*Room, force bool) []string {
+	resetting.Lock()
+	defer resetting.Unlock()
+	policy := ResetWhenEmpty
+	if z != nil {
+		policy = z.resetPolicy
+	}
+	if !force && (policy == ResetNever ||
+		(policy == ResetWhenEmpty && hasPlayers(rooms))) {
+		return nil
+	}
+	made := []string{}
+	for _, r := range(rooms) {
+		made = append(made, resetInQueue(r)...)
+	}
+	name := "rooms without a zone"
+	if z != nil {
+		name = "zone " + z.name
+	}
+	for _, note := range(made) {
+		Log("[reset]", name + ",", note)
+	}
+	return made
+}
+
+// ResetAll resets every zone, each as its policy allows
+func ResetAll(u *Universe) {
+	byZone := make(map[*Zone][]*Room)
+	for _, r := range(u.Rooms) {
+		if len(r.resets) > 0 {
+			byZone[r.zone] = append(byZone[r.zone], r)
+		}
+	}
+	for z, rooms := range(byZone) {
+		if z != nil {
+			// Its players may be in rooms without resets
+			rooms = z.Rooms()
+		} else {
+			sort.Sort(roomsByID(rooms))
+		}
+		ResetRooms(z, rooms, false)
+	}
+}
+
+/*
+ Resetter is a TimeListener which runs ResetAll every interval
+ heartbeats. The rooms themselves make what their resets need.
+ */
+type Resetter struct {
+	universe *Universe
+	ping chan int
+	interval int
+	ticks int
+}
+
+func (r *Resetter) Ping() chan int { return r.ping }
+
+func StartResets(u *Universe, interval int) *Resetter {
+	r := &Resetter{universe: u, ping: make(chan int), interval: interval}
+	u.Add(r)
+	go r.run()
+	return r
+}
+
+func (r *Resetter) run() {
+	for {
+		<- r.ping
+		r.ticks++
+		if r.interval > 0 && r.ticks % r.interval == 0 {
+			ResetAll(r.universe)
+		}
+	}
+}
+
+const roomResetUsage = `Rreset usage:
+  rreset                                   list this room's resets
+  rreset add [count] [prototype][, max n]  keep count of prototype here
+  rreset remove [number]                   remove a reset from the list
+  rreset now                               reset this room's zone now
+`
+
+func roomResetCommand(p *Player, args []string) {
+	r := p.room
+	if len(args) == 0 {
+		if len(r.resets) == 0 {
+			p.WriteString("This room has no resets.\n")
+		}
+		for i, rule := range(r.resets) {
+			p.WriteString(fmt.Sprintf("%d) %s\n", i+1, rule))
+		}
+		return
+	}
+	if !requireBuilder(p) {
+		return
+	}
+	switch {
+	case args[0] == "add" && len(args) > 1:
+		rule, err := ParseResetRule(strings.Join(args[1:], " "))
+		if err != nil {
+			p.WriteString("Error: " + err.Error() + ".\n")
+			return
+		}
+		if _, ok := Prototypes[rule.Prototype]; !ok {
+			p.WriteString("No prototype " + rule.Prototype + ".\n")
+			return
+		}
+		EditRoomResets(p, r, append(append([]ResetRule{}, r.resets...), rule))
+		p.WriteString("Added reset: " + rule.String() + ".\n")
+	case args[0] == "remove" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 || n > len(r.resets) {
+			p.WriteString("No reset " + args[1] + ".\n")
+			return
+		}
+		rules := append([]ResetRule{}, r.resets[:n-1]...)
+		EditRoomResets(p, r, append(rules, r.resets[n:]...))
+		p.WriteString("Removed reset " + args[1] + ".\n")
+	case args[0] == "now" && len(args) == 1:
+		rooms := []*Room{r}
+		if r.zone != nil {
+			rooms = r.zone.Rooms()
+		}
+		made := ResetRooms(r.zone, rooms, true)
+		p.WriteString(fmt.Sprintf("Reset made %d objects.\n", len(made)))
+	default:
+		p.WriteString(roomResetUsage)
+	}
+}
@@ -0,0 +1,128 @@
+package mud
+
+import ("testing"
+	"time")
+
+func TestParseResetRule(t *testing.T) {
+	for _, text := range([]string{"1 ball", "2 brass key", "1 puritan, max 1"}) {
+		rule, err := ParseResetRule(text)
+		if err != nil {
+			t.Errorf("ParseResetRule(%q) returned error: %s", text, err)
+		} else if rule.String() != text {
+			t.Errorf("%q came back as %q", text, rule.String())
+		}
+	}
+	for _, text := range([]string{"ball", "0 ball", "1", "1 ball, most 2", "1 ball, max 0"}) {
+		if _, err := ParseResetRule(text); err == nil {
+			t.Errorf("ParseResetRule(%q) should fail", text)
+		}
+	}
+}
+
+func TestResetNeeded(t *testing.T) {
+	cases := []struct {
+		rule ResetRule
+		inRoom, inWorld, want int
+	}{
+		{ResetRule{Count: 1, Prototype: "ball"}, 0, 5, 1},
+		{ResetRule{Count: 3, Prototype: "ball"}, 1, 1, 2},
+		{ResetRule{Count: 1, Prototype: "ball"}, 2, 2, 0},
+		{ResetRule{Count: 1, Prototype: "puritan", WorldMax: 1}, 0, 1, 0},
+		{ResetRule{Count: 3, Prototype: "ball", WorldMax: 4}, 0, 2, 2},
+	}
+	for _, c := range(cases) {
+		if got := c.rule.Needed(c.inRoom, c.inWorld); got != c.want {
+			t.Errorf("%s with %d here and %d in all: needed %d, want %d",
+				c.rule, c.inRoom, c.inWorld, got, c.want)
+		}
+	}
+}
+
+func init() {
+	Prototypes["test ball"] = func(u *Universe) PhysicalObject {
+		return &testThing{testObject: testObject{name: "A ball"}, handle: "test ball"}
+	}
+}
+
+func TestResetPolicies(t *testing.T) {
+	u := testUniverse()
+	z := NewZone(u, "Park")
+	z.SetResetPolicy(ResetWhenEmpty)
+	r := NewRoom(u, 0, "A lawn.")
+	r.zone = z
+	r.resets = []ResetRule{{Count: 1, Prototype: "test ball"}}
+	rooms := []*Room{r}
+
+	r.players[1] = &Player{}
+	if made := ResetRooms(z, rooms, false); len(made) != 0 {
+		t.Errorf("an occupied zone shouldn't reset when empty, made %v", made)
+	}
+	if made := ResetRooms(z, rooms, true); len(made) != 1 {
+		t.Errorf("forcing should reset an occupied zone, made %v", made)
+	}
+	r.RemoveChild(r.PhysicalObjects()[0])
+	delete(r.players, 1)
+
+	z.resetPolicy = ResetNever
+	if made := ResetRooms(z, rooms, false); len(made) != 0 {
+		t.Errorf("a zone which never resets made %v", made)
+	}
+	z.resetPolicy = ResetWhenEmpty
+	if made := ResetRooms(z, rooms, false); len(made) != 1 {
+		t.Errorf("an empty zone should reset, made %v", made)
+	}
+	if made := ResetRooms(z, rooms, false); len(made) != 0 {
+		t.Errorf("the ball is already there, but made %v", made)
+	}
+
+	// A player in a room without resets still keeps the zone from resetting
+	r.RemoveChild(r.PhysicalObjects()[0])
+	path := NewRoom(u, 0, "A path.")
+	path.zone = z
+	path.players[1] = &Player{}
+	ResetAll(u)
+	if len(r.PhysicalObjects()) != 0 {
+		t.Error("an occupied zone shouldn't reset when empty")
+	}
+	delete(path.players, 1)
+	ResetAll(u)
+	if len(r.PhysicalObjects()) != 1 {
+		t.Error("the zone should reset once empty")
+	}
+}
+
+func TestCountInWorld(t *testing.T) {
+	u := testUniverse()
+	r := NewRoom(u, 0, "A shed.")
+	ball := func() PhysicalObject { o, _ := MakePrototype(u, "test ball"); return o }
+	box := func(contents... PhysicalObject) PhysicalObject {
+		return &testChest{testContainer: testContainer{contents: contents}}
+	}
+	r.AddChild(ball())
+	r.AddChild(box(ball()))
+	p := NewPlayer(u, "Alice")
+	p.inventory.Add(box(ball(), box(ball())))
+	u.Players[1] = p
+
+	if n := CountInWorld(u, "test ball"); n != 4 {
+		t.Errorf("expected 4 balls, in rooms, inventories and containers, got %d", n)
+	}
+}
+
+func TestResetSkipsDeletedRooms(t *testing.T) {
+	u := testUniverse()
+	r := NewRoom(u, 0, "A ruin.")
+	r.resets = []ResetRule{{Count: 1, Prototype: "test ball"}}
+	DeleteRoom(r)
+
+	finished := make(chan []string)
+	go func() { finished <- ResetRooms(nil, []*Room{r}, true) }()
+	select {
+	case made := <- finished:
+		if len(made) != 0 {
+			t.Errorf("a deleted room shouldn't be reset, made %v", made)
+		}
+	case <- time.After(time.Second):
+		t.Fatal("resetting a deleted room shouldn't wait for its queue")
+	}
+}
@@ -3,10 +3,14 @@ package mud
 import ("strconv"; "strings")
 
 func init() {
-	PersistentKeys["room"] = []string{ "id", "text", "persisters" }
+	PersistentKeys["room"] = []string{ "id", "text", "persisters",
+		"zone", "properties", "coords", "flags", "extraDescs", "resets" }
 	PersistentKeys["roomConnect"] = []string{ 
-		"id", "aExitName", 
-		"bExitName", "roomAId", "roomBId" }
+		"id", "kind", "aExitName", 
+		"bExitName", "roomAId", "roomBId",
+		"oneWay", "hidden", "condition", "refusal", "discoveredBy",
+		"aDepart", "aArrive", "bDepart", "bArrive" }
+	RoomConnKinds["simple"] = loadSimpleConnCreator
 
 	containerHelper := new(FlexObjHandlerPair)
 	containerHelper.Add = func(fc *FlexContainer, o interface{}) {
@@ -22,8 +26,17 @@ func init() {
 type RoomID int
 type RoomSide int
 
-type RoomConnCreator func() *SimpleRoomConnection
-type RoomConnector func(a *Room, b *Room) *SimpleRoomConnection
+type RoomConnCreator func() RoomConnection
+type RoomConnector func(a *Room, b *Room) RoomConnection
+
+/*
+ RoomConnKindLoader rebuilds the RoomConnCreator for a saved
+ connection from its persisted values.
+ */
+type RoomConnKindLoader func(universe *Universe, vals Pvals) RoomConnCreator
+
+// Map of [roomConnect kind] -> loader, used by LoadRoomConn
+var RoomConnKinds = make(map[string]RoomConnKindLoader)
 
 const (
 	SideA RoomSide = iota
@@ -45,44 +58,96 @@ type Room struct {
 	exits []RoomExitInfo
 	stimuliBroadcast chan Stimulus
 	interactionQueue chan InterObjectAction
+	// closed when the room is deleted, to stop its goroutines
+	done chan bool
 	universe *Universe
+	zone *Zone
+	properties map[string]string
+	coords *Coord
+	flags map[string]bool
+	extraDescs map[string]string
+	resets []ResetRule
 }
 
 type RoomConnection interface {
+	Persister
+	ID() int
 	RoomA() *Room
 	RoomB() *Room
 	AExitName() string
 	BExitName() string
+	SetRooms(a *Room, b *Room)
+	SetID(id int)
+	Options() *ExitOptions
+	// CanPass reports whether p may travel through the connection
+	// from side, and if not, why.
+	CanPass(p *Player, from RoomSide) (bool, string)
+	// State is shown after the exit name by ExitNames, "" for none.
+	State() string
 }
 
 type SimpleRoomConnection struct {
-	Persister
 	RoomConnection
 	id int
 	roomA, roomB *Room
 	aExitName, bExitName string
+	options ExitOptions
 }
 
+func (rc SimpleRoomConnection) ID() int { return rc.id }
 func (rc SimpleRoomConnection) RoomA() *Room { return rc.roomA }
 func (rc SimpleRoomConnection) RoomB() *Room { return rc.roomB }
 func (rc SimpleRoomConnection) AExitName() string { return rc.aExitName }
 func (rc SimpleRoomConnection) BExitName() string { return rc.bExitName }
+func (rc SimpleRoomConnection) State() string { return "" }
+func (rc *SimpleRoomConnection) SetID(id int) { rc.id = id }
+func (rc *SimpleRoomConnection) Options() *ExitOptions { return &rc.options }
+// SetRooms joins the connection into the world, before others can see it
+func (rc *SimpleRoomConnection) SetRooms(a *Room, b *Room) {
+	rc.roomA, rc.roomB = a, b
+	// Made now, so Discover never replaces the map while it is read
+	if rc.options.discoveredBy == nil {
+		rc.options.discoveredBy = make(map[string]bool)
+	}
+}
+
+func (rc SimpleRoomConnection) CanPass(p *Player, from RoomSide) (bool, string) {
+	if from == SideB && rc.options.OneWay {
+		return false, "You can't go that way.\n"
+	}
+	if rc.options.Condition != "" &&
+		!CheckExitCondition(p, rc.options.Condition) {
+		if rc.options.Refusal != "" {
+			return false, rc.options.Refusal + "\n"
+		}
+		return false, "Something prevents you from going that way.\n"
+	}
+	return true, ""
+}
 
 func (rc SimpleRoomConnection) PersistentValues() map[string]interface{} {
 	vals := make(map[string]interface{})
 	if(rc.id > 0) {
 		vals["id"] = strconv.Itoa(rc.id)
 	}
+	vals["kind"] = "simple"
 	vals["roomAId"] = strconv.Itoa(rc.roomA.id)
 	vals["roomBId"] = strconv.Itoa(rc.roomB.id)
 	vals["aExitName"] = rc.aExitName
 	vals["bExitName"] = rc.bExitName
+	rc.options.persistentValues(vals)
 	return vals
 }
 
 func (rc *SimpleRoomConnection) Save() string {
+	return rc.saveValues(rc.PersistentValues())
+}
+
+// saveValues is shared by connection kinds which embed
+// SimpleRoomConnection and extend its PersistentValues.
+func (rc *SimpleRoomConnection) saveValues(vals map[string]interface{}) string {
 	universe := rc.roomA.universe
-	outID := universe.Store.SaveStructure("roomConnect",rc.PersistentValues())
+	outID := universe.Store.SaveStructure("roomConnect",vals)
 	if(rc.id == 0) {
 		rc.id, _ = strconv.Atoi(outID)
 		universe.Store.AddToGlobalSet("roomConnects", outID)
@@ -90,25 +155,48 @@ func (rc *SimpleRoomConnection) Save() string {
 	return outID
 }
 
-func LoadRoomConn(universe *Universe, id int) *SimpleRoomConnection {
+func LoadRoomConn(universe *Universe, id int) RoomConnection {
 	//Log("Entering LoadRoomConn")
+	fullDbUrl := FieldJoin(":","roomConnect",strconv.Itoa(id))
 	vals := universe.Store.LoadStructure(PersistentKeys["roomConnect"],
-		FieldJoin(":","roomConnect",strconv.Itoa(id)))
+		fullDbUrl)
+	kind, ok := vals["kind"].(string)
+	if !ok {
+		kind = "simple"
+	}
+	kindLoader, ok := RoomConnKinds[kind]
+	if !ok {
+		Log("[WARN] unknown roomConnect kind", kind, "for", fullDbUrl)
+		return nil
+	}
+	if extraKeys, ok := PersistentKeys[FieldJoin(":","roomConnect",kind)]; ok {
+		extraVals := universe.Store.LoadStructure(extraKeys, fullDbUrl)
+		for k, v := range(extraVals) { vals[k] = v }
+	}
+	rc := connectValues(universe, kindLoader, vals)
+	rc.SetID(id)
+	return rc
+}
+
+// connectValues connects the rooms in vals as a connection of its kind
+func connectValues(universe *Universe, kindLoader RoomConnKindLoader, vals Pvals) RoomConnection {
 	roomAIdStr, _ := vals["roomAId"].(string)
 	roomBIdStr, _ := vals["roomBId"].(string)
-	aExitName,_ := vals["aExitName"].(string)
-	bExitName,_ := vals["bExitName"].(string)
-	cc := SimpleRoomConnectCreator(aExitName,bExitName)
-	conn := ConnectWithConnCreator(cc)
+	conn := ConnectWithConnCreator(
+		WithExitOptions(kindLoader(universe, vals), loadExitOptions(vals)))
 	roomAId,_ := strconv.Atoi(roomAIdStr)
 	roomBId,_ := strconv.Atoi(roomBIdStr)
-	rc := conn(universe.Rooms[roomAId],universe.Rooms[roomBId])
-	rc.id = id
-	return rc
+	return conn(universe.Rooms[roomAId],universe.Rooms[roomBId])
+}
+
+func loadSimpleConnCreator(universe *Universe, vals Pvals) RoomConnCreator {
+	aExitName,_ := vals["aExitName"].(string)
+	bExitName,_ := vals["bExitName"].(string)
+	return SimpleRoomConnectCreator(aExitName,bExitName)
 }
 
 func SimpleRoomConnectCreator(a string, b string) RoomConnCreator {
-	return func() *SimpleRoomConnection {
+	return func() RoomConnection {
 		conn := new(SimpleRoomConnection)
 		conn.aExitName, conn.bExitName = a, b
 		return conn
@@ -122,14 +210,20 @@ var NEtoSWRoomConnection = SimpleRoomConnectCreator("northeast","southwest")
 var NWtoSERoomConnection = SimpleRoomConnectCreator("northwest","southeast")
 
 func ConnectWithConnCreator(exitGen RoomConnCreator) RoomConnector {
-	return func(a *Room, b *Room) *SimpleRoomConnection {
+	return func(a *Room, b *Room) RoomConnection {
 		roomConn := exitGen()
-		roomConn.roomA = a
-		roomConn.roomB = b
+		roomConn.SetRooms(a, b)
 		reiA := RoomExitInfo{exitSide: SideA, exit: roomConn}
 		reiB := RoomExitInfo{exitSide: SideB, exit: roomConn}
 		a.exits = append(a.exits, reiA)
-		b.exits = append(b.exits, reiB)
+		if !roomConn.Options().OneWay {
+			b.exits = append(b.exits, reiB)
+		}
+		for _, rei := range([]*RoomExitInfo{&reiA, &reiB}) {
+			if conflict := placeAcross(rei); conflict != nil {
+				Log("[WARN] coordinate conflict:", conflict)
+			}
+		}
 		a.universe.Add(roomConn)
 		return roomConn
 	}
@@ -143,8 +237,12 @@ var ConnectNWtoSE = ConnectWithConnCreator(NWtoSERoomConnection)
 
 func (r *Room) ActionQueue() {
 	for {
-		action := <- r.interactionQueue
-		action.Exec()
+		select {
+		case action := <- r.interactionQueue:
+			action.Exec()
+		case <- r.done:
+			return
+		}
 	}
 }
 
@@ -154,10 +252,14 @@ func (r *Room) Perceivers() []Perceiver {
 
 func (r *Room) FanOutBroadcasts() {
 	for {
-		broadcast := <- r.stimuliBroadcast
-		for _,p := range r.Perceivers() { 
-			//Log("fanning broadcast to ",p)
-			p.StimuliChannel() <- broadcast 
+		select {
+		case broadcast := <- r.stimuliBroadcast:
+			for _,p := range r.Perceivers() { 
+				//Log("fanning broadcast to ",p)
+				p.StimuliChannel() <- broadcast 
+			}
+		case <- r.done:
+			return
 		}
 	}
 }
@@ -165,26 +267,52 @@ func (r *Room) FanOutBroadcasts() {
 func (r *RoomExitInfo) Name() string {
 	if(r.exitSide == SideA) {
 		return r.exit.AExitName()
-	} else {
-		return r.exit.BExitName()
 	}
-	return ""
+	return r.exit.BExitName()
 }
 
 func (r *RoomExitInfo) OtherSide() *Room {
 	if(r.exitSide == SideA) {
 		return r.exit.RoomB()
-	} else {
-		return r.exit.RoomA()
 	}
-	return nil
+	return r.exit.RoomA()
 }
 
+// ReverseName is the name of this exit as seen from the other side.
+func (r *RoomExitInfo) ReverseName() string {
+	if(r.exitSide == SideA) {
+		return r.exit.BExitName()
+	}
+	return r.exit.AExitName()
+}
+
+func (r *RoomExitInfo) Side() RoomSide { return r.exitSide }
+func (r *RoomExitInfo) Connection() RoomConnection { return r.exit }
+
+// Label is the exit name as listed to players, with any state.
+func (r *RoomExitInfo) Label() string {
+	if state := r.exit.State(); state != "" {
+		return r.Name() + " (" + state + ")"
+	}
+	return r.Name()
+}
+
+// DescribeHook adds text to a room's description, or returns ""
+type DescribeHook func(r *Room, toPlayer *Player) string
+
+// Hooks run by Room.Describe, e.g. for time-of-day text
+var DescribeHooks []DescribeHook
+
 func (r *Room) Describe(toPlayer *Player) string {
 	roomText := r.text
+	for _, hook := range(DescribeHooks) {
+		if text := hook(r, toPlayer); text != "" {
+			roomText += "\n" + text
+		}
+	}
 	objectsText := r.DescribeObjects(toPlayer)
 	playersText := r.DescribePlayers(toPlayer)
-	exitsText := "Exits: " + r.ExitNames()
+	exitsText := "Exits: " + r.ExitNames(toPlayer)
 	
 	return roomText + Divider() + 
 		objectsText + Divider() + 
@@ -195,7 +323,7 @@ func (r *Room) DescribeObjects(toPlayer *Player) string {
 	objTextBuf := "Sitting here is/are:\n"
 	for _,obj := range r.PhysicalObjects() {
 		if obj != nil && obj.Visible() {
-			objTextBuf += obj.Description()
+			objTextBuf += StackDescription(obj)
 			objTextBuf += "\n"
 		}
 	}
@@ -224,8 +352,25 @@ func (r *Room) RemoveChild(o interface{}) {
 	r.children.Remove(o)
 }
 
+// Broadcast sends s to everyone in the room, unless it has been deleted
 func (r *Room) Broadcast(s Stimulus) {
-	r.stimuliBroadcast <- s
+	select {
+	case r.stimuliBroadcast <- s:
+	case <- r.done:
+	}
+}
+
+/*
+ TryBroadcast sends s to r if its stimuli queue has space, returning
+ false otherwise. Timers use it so a busy room can't hold them up.
+ */
+func (r *Room) TryBroadcast(s Stimulus) bool {
+	select {
+	case r.stimuliBroadcast <- s:
+		return true
+	default:
+		return false
+	}
 }
 
 func (r Room) PersistentValues() map[string]interface{} {
@@ -236,6 +381,20 @@ func (r Room) PersistentValues() map[string]interface{} {
 	vals["text"] = r.text
 	vals["persisters"] = castAsPersistents(
 		r.children.AllObjects["Persistents"])
+	if r.zone != nil {
+		vals["zone"] = strconv.Itoa(r.zone.id)
+	} else {
+		vals["zone"] = ""
+	}
+	vals["properties"] = propertyStrings(r.properties)
+	if r.coords != nil {
+		vals["coords"] = r.coords.String()
+	} else {
+		vals["coords"] = ""
+	}
+	vals["flags"] = r.Flags()
+	vals["extraDescs"] = propertyStrings(r.extraDescs)
+	vals["resets"] = resetStrings(r.resets)
 	return vals
 }
 
@@ -248,16 +407,42 @@ func (r *Room) Save() string {
 	return outID
 }
 
+func (r *Room) DBFullName() string {
+	return FieldJoin(":","room",strconv.Itoa(r.id))
+}
+
 func LoadRoom(universe *Universe, id int) *Room {
 	vals := universe.Store.LoadStructure(PersistentKeys["room"],
 		FieldJoin(":","room",strconv.Itoa(id)))
 	Log("LoadRoom vals",vals)
 	if textStr, ok := vals["text"].(string); ok {
 		r := NewRoom(universe, id, textStr)
+		if zoneId, err := strconv.Atoi(stringVal(vals, "zone")); err == nil {
+			r.zone = universe.Zones[zoneId]
+		}
+		if props, ok := vals["properties"].([]string); ok {
+			r.properties = parsePropertyStrings(props)
+		}
+		if c, err := ParseCoord(stringVal(vals, "coords")); err == nil {
+			r.SetCoords(c)
+		}
+		if flags, ok := vals["flags"].([]string); ok {
+			for _, flag := range(flags) { r.flags[flag] = true }
+		}
+		if extras, ok := vals["extraDescs"].([]string); ok {
+			r.extraDescs = parsePropertyStrings(extras)
+		}
+		if resets, ok := vals["resets"].([]string); ok {
+			r.resets = parseResetStrings(resets)
+		}
 		if persisterIds, ok := vals["persisters"].([]string); ok {
 			for _,pid := range(persisterIds) {
-				p := LoadArbitrary(universe, pid)
-				r.AddChild(p)
+				if p := LoadArbitrary(universe, pid); p != nil {
+					if o, ok := p.(PhysicalObject); ok {
+						o.SetRoom(r)
+					}
+					r.AddChild(p)
+				}
 			}
 		}
 		return r
@@ -269,8 +454,12 @@ func NewRoom(universe *Universe, rid int, rtext string) *Room {
 	r := Room{id: rid, text: rtext, universe: universe}
 	r.stimuliBroadcast = make(chan Stimulus, 10)
 	r.interactionQueue = make(chan InterObjectAction, 10)
+	r.done = make(chan bool)
 	r.players = make(map[int]*Player)
 	r.exits = []RoomExitInfo{}
+	r.properties = make(map[string]string)
+	r.flags = make(map[string]bool)
+	r.extraDescs = make(map[string]string)
 	r.children = NewFlexContainer(
 		"PhysicalObjects",
 		"Persistents",
@@ -278,6 +467,10 @@ func NewRoom(universe *Universe, rid int, rtext string) *Room {
 		"Perceivers",
 		"CommandSources")
 	r.children.Meta["Room"] = &r
+	if r.id == 0 {
+		// Save now so the room is registered under its real ID
+		r.Save()
+	}
 	universe.Rooms[r.id] = &r
 	universe.Add(&r)
 
@@ -299,8 +492,47 @@ func (r *Room) WithExit(name string, found FoundExit, notFound func()) {
 	notFound()
 }
 
+// WithVisibleExit is WithExit, ignoring exits hidden from p
+func (r *Room) WithVisibleExit(p *Player, name string, found FoundExit, notFound func()) {
+	for _,exit := range(r.exits) {
+		if name == exit.Name() && exit.VisibleTo(p) {
+			found(&exit)
+			return
+		}
+	}
+	notFound()
+}
+
 func (r *Room) SetText(text string) { r.text = text }
 func (r *Room) Text() string { return r.text }
+func (r *Room) ID() int { return r.id }
+func (r *Room) Universe() *Universe { return r.universe }
+
+func (r *Room) Zone() *Zone { return r.zone }
+func (r *Room) SetZone(z *Zone) { r.zone = z }
+
+/*
+ Property returns the room's value for key, falling back to the
+ default set by the room's Zone. ok is false if neither sets it.
+ */
+func (r *Room) Property(key string) (value string, ok bool) {
+	if value, ok = r.properties[key]; ok {
+		return
+	}
+	if r.zone != nil {
+		value, ok = r.zone.properties[key]
+	}
+	return
+}
+
+// SetProperty sets key on this room only; "" removes it.
+func (r *Room) SetProperty(key string, value string) {
+	if value == "" {
+		delete(r.properties, key)
+	} else {
+		r.properties[key] = value
+	}
+}
 
 type PhysObjReceiver func(p *PhysicalObject)
 
@@ -308,15 +540,25 @@ func (r *Room) WithPhysObjects(handler PhysObjReceiver) {
 	for _,p := range(r.PhysicalObjects()) { handler(&p) }
 }
 
-func (r *Room) ExitNames() string {
-	exitNames := make([]string, len(r.exits))
-	for i,exit := range(r.exits) {
-		exitNames[i] = exit.Name()
+// ExitNames lists the exits viewer can see; a nil viewer sees no
+// hidden exits.
+func (r *Room) ExitNames(viewer *Player) string {
+	exitNames := []string{}
+	for _,exit := range(r.exits) {
+		if exit.VisibleTo(viewer) {
+			exitNames = append(exitNames, exit.Label())
+		}
 	}
 	return strings.Join(exitNames, ", ")
 }
 
-func (r *Room) Actions() chan InterObjectAction { return r.interactionQueue }
+// Act queues action to run in the room, unless it has been deleted
+func (r *Room) Act(action InterObjectAction) {
+	select {
+	case r.interactionQueue <- action:
+	case <- r.done:
+	}
+}
 
 func (r *Room) CommandSources() []CommandSource {
 	return castCmdSources(r.children.AllObjects["CommandSources"])
@@ -0,0 +1,179 @@
+package mud
+
+import ("sort"
+	"strings")
+
+func init() {
+	GlobalCommands["rflag"] = roomFlagCommand
+	GlobalCommands["recall"] = recall
+}
+
+// Room flags
+const (
+	FlagSafe = "safe"
+	FlagNoPioneer = "no-pioneer"
+	FlagPrivate = "private"
+	FlagIndoors = "indoors"
+	FlagNoDrop = "no-drop"
+	FlagNoTeleport = "no-teleport"
+	FlagRecall = "recall"
+)
+
+var roomFlagHelp = map[string]string{
+	FlagSafe: "no fighting",
+	FlagNoPioneer: "no building new rooms with pioneer",
+	FlagPrivate: "at most two players at a time",
+	FlagIndoors: "never outdoors, whatever the zone says",
+	FlagNoDrop: "nothing may be dropped",
+	FlagNoTeleport: "no teleporting in or out",
+	FlagRecall: "where recall takes players",
+}
+
+// Players allowed at once in a private room
+const PrivateRoomLimit = 2
+
+/*
+ RoomRule forbids a command in rooms with a flag. The dispatcher
+ checks CommandRules before running any command, so root code can
+ add rules for its own commands.
+ */
+type RoomRule struct {
+	Flag string
+	Message string
+}
+
+var CommandRules = map[string]RoomRule{
+	"pioneer": {FlagNoPioneer, "You can't build here.\n"},
+	"dig": {FlagNoPioneer, "You can't build here.\n"},
+	"drop": {FlagNoDrop, "You can't drop things here.\n"},
+	"recall": {FlagNoTeleport, "Something here prevents your recall.\n"},
+}
+
+func (r *Room) HasFlag(flag string) bool { return r.flags[flag] }
+
+func (r *Room) SetFlag(flag string, on bool) {
+	if on {
+		r.flags[flag] = true
+	} else {
+		delete(r.flags, flag)
+	}
+}
+
+// Flags returns the room's flags in order
+func (r *Room) Flags() []string {
+	flags := []string{}
+	for flag := range(r.flags) { flags = append(flags, flag) }
+	sort.Strings(flags)
+	return flags
+}
+
+// CanEdit is true for builders, if they own the room's zone
+func (r *Room) CanEdit(p *Player) bool {
+	return p.IsBuilder() && (r.zone == nil || r.zone.CanEdit(p))
+}
+
+// Forbids checks the CommandRules for a command in r
+func (r *Room) Forbids(command string) (string, bool) {
+	if rule, ok := CommandRules[command]; ok && r.HasFlag(rule.Flag) {
+		return rule.Message, true
+	}
+	return "", false
+}
+
+// CanEnter checks whether there is room for p in r
+func (r *Room) CanEnter(p *Player) (bool, string) {
+	if r.HasFlag(FlagPrivate) && len(r.players) >= PrivateRoomLimit {
+		if _, inside := r.players[p.id]; !inside {
+			return false, "That room is private, and already occupied.\n"
+		}
+	}
+	return true, ""
+}
+
+/*
+ Teleport moves p straight to r, unless either room is no-teleport
+ or r can't be entered. The reason is returned if p can't go.
+ */
+func Teleport(p *Player, r *Room) (bool, string) {
+	if p.room != nil && p.room.HasFlag(FlagNoTeleport) {
+		return false, "Something here holds you in place.\n"
+	}
+	if r.HasFlag(FlagNoTeleport) {
+		return false, "Something there keeps you out.\n"
+	}
+	if ok, reason := r.CanEnter(p); !ok {
+		return false, reason
+	}
+	placePlayer(r, p, "appears in a flash", "vanishes in a flash")
+	return true, ""
+}
+
+/*
+ RecallRoom is where recall takes p: the recall room of p's zone, or
+ failing that the lowest numbered recall room anywhere.
+ */
+func RecallRoom(p *Player) *Room {
+	if p.room != nil && p.room.zone != nil {
+		for _, r := range(p.room.zone.Rooms()) {
+			if r.HasFlag(FlagRecall) { return r }
+		}
+	}
+	var recallRoom *Room
+	for _, r := range(p.Universe.Rooms) {
+		if r.HasFlag(FlagRecall) && (recallRoom == nil || r.id < recallRoom.id) {
+			recallRoom = r
+		}
+	}
+	return recallRoom
+}
+
+func recall(p *Player, args []string) {
+	target := RecallRoom(p)
+	if target == nil {
+		p.WriteString("There is nowhere to recall to.\n")
+		return
+	}
+	if target == p.room {
+		p.WriteString("You are already here.\n")
+		return
+	}
+	if ok, reason := Teleport(p, target); !ok {
+		p.WriteString(reason)
+		return
+	}
+	Look(p, []string{})
+}
+
+func roomFlagCommand(p *Player, args []string) {
+	r := p.room
+	if len(args) == 0 {
+		if flags := r.Flags(); len(flags) > 0 {
+			p.WriteString("Room flags: " + strings.Join(flags, ", ") + "\n")
+		} else {
+			p.WriteString("This room has no flags.\n")
+		}
+		return
+	}
+	if !r.CanEdit(p) {
+		p.WriteString("You can't change this room.\n")
+		return
+	}
+	flag := args[0]
+	if _, known := roomFlagHelp[flag]; !known || len(args) != 1 {
+		flags := []string{}
+		for name := range(roomFlagHelp) { flags = append(flags, name) }
+		sort.Strings(flags)
+		usage := "Rflag usage: rflag [flag] toggles a flag. Flags are:\n"
+		for _, name := range(flags) {
+			usage += "  " + name + ": " + roomFlagHelp[name] + "\n"
+		}
+		p.WriteString(usage)
+		return
+	}
+	EditRoomFlag(p, r, flag, !r.HasFlag(flag))
+	if r.HasFlag(flag) {
+		p.WriteString("Flag " + flag + " set.\n")
+	} else {
+		p.WriteString("Flag " + flag + " cleared.\n")
+	}
+}
@@ -0,0 +1,55 @@
+package mud
+
+import "testing"
+
+func TestForbids(t *testing.T) {
+	r := quietRoom(testUniverse())
+	if _, forbidden := r.Forbids("drop"); forbidden {
+		t.Error("dropping should be allowed in a room without flags")
+	}
+	r.SetFlag(FlagNoDrop, true)
+	if message, forbidden := r.Forbids("drop"); !forbidden ||
+		message != "You can't drop things here.\n" {
+		t.Errorf("no-drop should forbid drop, got %q", message)
+	}
+	if _, forbidden := r.Forbids("take"); forbidden {
+		t.Error("no-drop shouldn't forbid commands without a rule")
+	}
+	r.SetFlag(FlagSafe, true)
+	if _, forbidden := r.Forbids("say"); forbidden {
+		t.Error("safe rooms shouldn't forbid talking")
+	}
+	r.SetFlag(FlagNoDrop, false)
+	if _, forbidden := r.Forbids("drop"); forbidden {
+		t.Error("drop should be allowed once no-drop is cleared")
+	}
+}
+
+func TestPrivateRoomCanEnter(t *testing.T) {
+	r := quietRoom(testUniverse())
+	r.SetFlag(FlagPrivate, true)
+	players := []*Player{}
+	for id := 1; id <= PrivateRoomLimit + 1; id++ {
+		p, _ := testPlayer(r)
+		p.id = id
+		players = append(players, p)
+	}
+	for _, p := range(players[:PrivateRoomLimit]) {
+		if ok, reason := r.CanEnter(p); !ok {
+			t.Fatalf("player %d should fit, was told %q", p.id, reason)
+		}
+		r.players[p.id] = p
+	}
+	late := players[PrivateRoomLimit]
+	if ok, reason := r.CanEnter(late); ok ||
+		reason != "That room is private, and already occupied.\n" {
+		t.Errorf("a full private room let player %d in: %q", late.id, reason)
+	}
+	if ok, _ := r.CanEnter(players[0]); !ok {
+		t.Error("players already inside should never be turned away")
+	}
+	r.SetFlag(FlagPrivate, false)
+	if ok, _ := r.CanEnter(late); !ok {
+		t.Error("a public room should have space for anyone")
+	}
+}
@@ -0,0 +1,120 @@
+package simple
+
+import ("fmt"
+	"mud"
+	"strconv")
+
+func init() {
+	mud.Loaders["container"] = LoadContainer
+	mud.PersistentKeys["container"] = []string{ "id", "prototype", "fields",
+		"capacity", "state", "keyHandle", "contents" }
+}
+
+/*
+ Container is a PhysicalObject holding others, filed in a
+ FlexContainer so those which persist are saved with it. It persists
+ as objects do (see persist.go), with its lid and contents.
+ */
+type Container struct {
+	*PhysicalObject
+	contents *mud.FlexContainer
+	capacity int
+	state mud.DoorState
+	keyHandle string
+}
+
+func (c *Container) Contents() []mud.PhysicalObject {
+	objects := []mud.PhysicalObject{}
+	for _, o := range(c.contents.AllObjects["PhysicalObjects"]) {
+		objects = append(objects, o.(mud.PhysicalObject))
+	}
+	return objects
+}
+func (c *Container) AddContent(o mud.PhysicalObject) {
+	c.contents.Add(o)
+	if c.registered {
+		registerObject(o)
+	}
+}
+func (c *Container) RemoveContent(o mud.PhysicalObject) { c.contents.Remove(o) }
+func (c *Container) Capacity() int { return c.capacity }
+func (c *Container) SetCapacity(n int) { c.capacity = n }
+func (c *Container) ContainerState() mud.DoorState { return c.state }
+func (c *Container) SetContainerState(s mud.DoorState) { c.state = s }
+func (c *Container) KeyHandle() string { return c.keyHandle }
+func (c *Container) SetKeyHandle(handle string) { c.keyHandle = handle }
+
+func (c *Container) PersistentValues() map[string]interface{} {
+	vals := c.PhysicalObject.PersistentValues()
+	vals["capacity"] = strconv.Itoa(c.capacity)
+	vals["state"] = strconv.Itoa(int(c.state))
+	vals["keyHandle"] = c.keyHandle
+	contents := []mud.Persister{}
+	for _, o := range(c.contents.AllObjects["Persistents"]) {
+		contents = append(contents, o.(mud.Persister))
+	}
+	vals["contents"] = contents
+	return vals
+}
+
+func (c *Container) Save() string {
+	outID := c.universe.Store.SaveStructure("container", c.PersistentValues())
+	if c.id == 0 {
+		c.id, _ = strconv.Atoi(outID)
+	}
+	c.register()
+	return outID
+}
+
+func (c *Container) DBFullName() string {
+	return fmt.Sprintf("container:%d", c.id)
+}
+
+/*
+ NewContainer makes an open, empty container holding up to capacity
+ (counting nested contents).
+ */
+func NewContainer(u *mud.Universe, capacity int) *Container {
+	c := &Container{PhysicalObject: NewPhysicalObject(u), capacity: capacity}
+	c.visible = true
+	c.contents = mud.NewFlexContainer("PhysicalObjects", "Persistents")
+	c.saveAs = c
+	return c
+}
+
+func LoadContainer(u *mud.Universe, id int) interface{} {
+	vals := u.Store.LoadStructure(mud.PersistentKeys["container"],
+		mud.FieldJoin(":", "container", strconv.Itoa(id)))
+	if _, ok := vals["id"]; !ok {
+		return nil
+	}
+	var c *Container
+	if o, ok := MakeFromPrototype(u, vals); ok {
+		if c, ok = o.(*Container); !ok {
+			mud.Log("[warn] prototype", vals["prototype"], "doesn't make a container")
+			return o
+		}
+	} else {
+		c = NewContainer(u, 0)
+	}
+	c.id = id
+	c.register()
+	ApplyFields(vals, c.setField)
+	c.capacity, _ = strconv.Atoi(stringVal(vals, "capacity"))
+	state, _ := strconv.Atoi(stringVal(vals, "state"))
+	c.state = mud.DoorState(state)
+	c.keyHandle = stringVal(vals, "keyHandle")
+	if contents, ok := vals["contents"].([]string); ok {
+		for _, name := range(contents) {
+			if o, ok := mud.LoadArbitrary(u, name).(mud.PhysicalObject); ok {
+				c.AddContent(o)
+			}
+		}
+	}
+	return c
+}
+
+func stringVal(vals mud.Pvals, key string) string {
+	s, _ := vals[key].(string)
+	return s
+}
@@ -0,0 +1,29 @@
+package simple
+
+import ("mud"
+	"testing")
+
+func TestContainerContentsChangeSaved(t *testing.T) {
+	u := testUniverse()
+	bag := NewContainer(u, 5)
+	apple, pear := NewPhysicalObject(u), NewPhysicalObject(u)
+	apple.SetDescription("An apple")
+	pear.SetDescription("A pear")
+	bag.AddContent(apple)
+	bag.AddContent(pear)
+	apple.Save()
+	pear.Save()
+	bag.Save()
+
+	bag.RemoveContent(apple)
+	bag.SetContainerState(mud.DoorClosed)
+	bag.Save()
+	loaded := LoadContainer(u, bag.id).(*Container)
+	if contents := loaded.Contents(); len(contents) != 1 ||
+		contents[0].Description() != "A pear" {
+		t.Errorf("the bag should only hold the pear, got %v", contents)
+	}
+	if loaded.ContainerState() != mud.DoorClosed {
+		t.Errorf("the bag should load closed, not %s", loaded.ContainerState())
+	}
+}
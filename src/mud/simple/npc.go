@@ -24,24 +24,34 @@ type NPC struct {
 	visible bool
 	carryable bool
 	description string
+	longDescription string
+	prototype string
+	defaults map[string]string
 	Meta map[string]interface{}
 }
 
 func (n NPC) ID() int { return n.id }
-func (n NPC) SetId(id int) { n.id = id }
+func (n *NPC) SetId(id int) { n.id = id }
 func (n NPC) Name() string { return n.name }
+func (n *NPC) SetName(name string) { n.name = name }
 func (n NPC) Description() string { return n.description }
 func (n *NPC) SetDescription(d string) { n.description = d }
+func (n NPC) LongDescription() string { return n.longDescription }
+func (n *NPC) SetLongDescription(d string) { n.longDescription = d }
 func (n NPC) Carryable() bool { return n.carryable }
 func (n *NPC) SetCarryable(c bool) { n.carryable = c}
 func (n NPC) Visible() bool { return n.visible }
 func (n *NPC) SetVisible(v bool) { n.visible = v }
+func (n *NPC) PrototypeName() string { return n.prototype }
 func (n *NPC) SetUniverse(u *mud.Universe) { n.universe = u }
 
 func (n *NPC) SetRoom(r *mud.Room) { n.room = r }
 func (n *NPC) Room() *mud.Room { return n.room }
 
 func (n *NPC) TextHandles() []string { return n.textHandles }
+func (n *NPC) SetTextHandles(handles... string) {
+	n.textHandles = handles
+}
 
 func (n *NPC) AddStimHandler(stimName string, handler SimpleStimulusHandler) {
 	mud.Log("AddStimHandler", stimName, handler)
@@ -70,6 +80,31 @@ func (npc *NPC) Commands() map[string]mud.Command {
 	return npc.localCommands
 }
 
+// Go moves the NPC through the named exit, if it is OpenToNPCs
+func (n *NPC) Go(exitName string) bool {
+	moved := false
+	n.room.WithExit(exitName, func(exit *mud.RoomExitInfo) {
+		if !exit.OpenToNPCs() {
+			return
+		}
+		mud.MoveObject(n, exit)
+		moved = true
+	}, func() {})
+	return moved
+}
+
+/*
+ WalkTo takes one step along the shortest path to target, returning
+ false once there or if there is no way there. It is meant to be
+ called from time or stimulus handlers.
+ */
+func (n *NPC) WalkTo(target *mud.Room) bool {
+	if exitName, ok := mud.NextStep(n.room, target); ok {
+		return n.Go(exitName)
+	}
+	return false
+}
+
 func NewNPC(u *mud.Universe) *NPC {
 	npc := new(NPC)
 	npc.universe = u
@@ -77,6 +112,7 @@ func NewNPC(u *mud.Universe) *NPC {
 	npc.supportedStimuli = make(map[string]SimpleStimulusHandler)
 	npc.stimuli = make(chan mud.Stimulus, 5)
 	npc.Meta = make(map[string]interface{})
+	u.Add(npc)
 	go mud.StimuliLoop(npc)
 	return npc
 }
\ No newline at end of file
@@ -0,0 +1,330 @@
+package simple
+
+import ("fmt"
+	"mud"
+	"sort"
+	"strconv"
+	"strings")
+
+func init() {
+	mud.Loaders["object"] = LoadObject
+	mud.PersistentKeys["object"] = []string{ "id", "prototype", "fields" }
+	mud.Loaders["npc"] = LoadNPC
+	mud.PersistentKeys["npc"] = []string{ "id", "prototype", "fields" }
+}
+
+/*
+ Objects and NPCs from this package persist generically: each record
+ holds the name of the Prototype which made the object and the fields
+ it has changed since, as "name=value". Loading makes the prototype
+ again, which restores its handlers, then applies the changes. Objects
+ made without a prototype save every field. Other packages can persist
+ their objects the same way with Overrides, ApplyFields and
+ MakeFromPrototype.
+ */
+
+// Overrides lists the fields which differ from defaults
+func Overrides(fields map[string]string, defaults map[string]string) []string {
+	changed := []string{}
+	for name, value := range(fields) {
+		if d, ok := defaults[name]; !ok || d != value {
+			changed = append(changed, name + "=" + value)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// ApplyFields calls set with each field a record changed
+func ApplyFields(vals mud.Pvals, set func(name string, value string)) {
+	fields, _ := vals["fields"].([]string)
+	for _, field := range(fields) {
+		if nv := strings.SplitN(field, "=", 2); len(nv) == 2 {
+			set(nv[0], nv[1])
+		}
+	}
+}
+
+// MakeFromPrototype makes what a record's prototype makes, if it can
+func MakeFromPrototype(u *mud.Universe, vals mud.Pvals) (mud.PhysicalObject, bool) {
+	name := stringVal(vals, "prototype")
+	if name == "" {
+		return nil, false
+	}
+	o, ok := mud.MakePrototype(u, name)
+	if !ok {
+		mud.Log("[warn] no prototype", name, "to load; its defaults are lost")
+	}
+	return o, ok
+}
+
+func (p *PhysicalObject) fields() map[string]string {
+	fields := map[string]string{
+		"description": p.description,
+		"longDescription": p.longDescription,
+		"textHandles": strings.Join(p.textHandles, ","),
+		"visible": strconv.FormatBool(p.visible),
+		"carryable": strconv.FormatBool(p.carryable),
+		"lightSource": strconv.FormatBool(p.lightSource),
+		"lit": strconv.FormatBool(p.lit),
+		"weight": strconv.Itoa(p.weight),
+		"bulk": strconv.Itoa(p.bulk),
+		"value": strconv.Itoa(int(p.value)),
+		"slots": strings.Join(p.slots, ","),
+		"modifiers": modifierString(p.modifiers),
+		"stackable": strconv.FormatBool(p.stackable),
+		"count": strconv.Itoa(p.count),
+	}
+	if p.durability != nil {
+		fields["condition"] = strconv.Itoa(p.durability.Condition)
+	}
+	return fields
+}
+
+// modifierString writes modifiers as "stat:n", in order
+func modifierString(modifiers map[string]int) string {
+	mods := []string{}
+	for stat, n := range(modifiers) {
+		mods = append(mods, stat + ":" + strconv.Itoa(n))
+	}
+	sort.Strings(mods)
+	return strings.Join(mods, ",")
+}
+
+func parseModifierString(s string) map[string]int {
+	modifiers := make(map[string]int)
+	for _, mod := range(strings.Split(s, ",")) {
+		if parts := strings.SplitN(mod, ":", 2); len(parts) == 2 {
+			modifiers[parts[0]], _ = strconv.Atoi(parts[1])
+		}
+	}
+	return modifiers
+}
+
+// splitList reads a list saved comma separated, nil if it was empty
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func (p *PhysicalObject) setField(name string, value string) {
+	switch name {
+	case "description":
+		p.description = value
+	case "longDescription":
+		p.longDescription = value
+	case "textHandles":
+		p.textHandles = splitList(value)
+	case "visible":
+		p.visible = value == "true"
+	case "carryable":
+		p.carryable = value == "true"
+	case "lightSource":
+		p.lightSource = value == "true"
+	case "lit":
+		p.lit = value == "true"
+	case "weight":
+		p.weight, _ = strconv.Atoi(value)
+	case "bulk":
+		p.bulk, _ = strconv.Atoi(value)
+	case "value":
+		value, _ := strconv.Atoi(value)
+		p.value = mud.Currency(value)
+	case "slots":
+		p.slots = splitList(value)
+	case "modifiers":
+		p.modifiers = parseModifierString(value)
+	case "stackable":
+		p.stackable = value == "true"
+	case "count":
+		p.count, _ = strconv.Atoi(value)
+	case "condition":
+		if p.durability != nil {
+			p.durability.Condition, _ = strconv.Atoi(value)
+		}
+	}
+}
+
+// SetPrototypeName marks p as made by a prototype, taking its fields as the defaults
+func (p *PhysicalObject) SetPrototypeName(name string) {
+	p.prototype = name
+	p.defaults = p.fields()
+}
+
+func (p *PhysicalObject) ID() int { return p.id }
+
+func (p *PhysicalObject) PersistentValues() map[string]interface{} {
+	vals := make(map[string]interface{})
+	if p.id > 0 {
+		vals["id"] = strconv.Itoa(p.id)
+	}
+	vals["prototype"] = p.prototype
+	vals["fields"] = Overrides(p.fields(), p.defaults)
+	return vals
+}
+
+func (p *PhysicalObject) Save() string {
+	outID := p.universe.Store.SaveStructure("object", p.PersistentValues())
+	if p.id == 0 {
+		p.id, _ = strconv.Atoi(outID)
+	}
+	p.register()
+	return outID
+}
+
+func (p *PhysicalObject) DBFullName() string {
+	return fmt.Sprintf("object:%d", p.id)
+}
+
+/*
+ objectSaver has the universe save a PhysicalObject, or what it is
+ part of (such as a Container). The object can't be added itself, as
+ its Ping would have it taken for a TimeListener.
+ */
+type objectSaver struct {
+	object *PhysicalObject
+}
+
+func (s objectSaver) DBFullName() string { return s.object.saveAs.DBFullName() }
+func (s objectSaver) Save() string { return s.object.saveAs.Save() }
+func (s objectSaver) PersistentValues() map[string]interface{} {
+	return s.object.saveAs.PersistentValues()
+}
+
+/*
+ register has the universe keep p saved, once it is placed in the world
+ or saved. Objects which are never placed, like a shop's unsold stock,
+ aren't saved at all. A container's contents are registered with it.
+ */
+func (p *PhysicalObject) register() {
+	if p.registered || p.universe == nil {
+		return
+	}
+	p.registered = true
+	p.universe.Add(objectSaver{p})
+	if c, ok := p.saveAs.(*Container); ok {
+		for _, o := range(c.Contents()) { registerObject(o) }
+	}
+}
+
+func registerObject(o interface{}) {
+	if r, ok := o.(interface{ register() }); ok {
+		r.register()
+	}
+}
+
+// Destroyed stops the universe saving p once it is destroyed
+func (p *PhysicalObject) Destroyed() {
+	if p.universe != nil {
+		p.universe.Remove(objectSaver{p})
+	}
+}
+
+func LoadObject(u *mud.Universe, id int) interface{} {
+	vals := u.Store.LoadStructure(mud.PersistentKeys["object"],
+		mud.FieldJoin(":", "object", strconv.Itoa(id)))
+	if _, ok := vals["id"]; !ok {
+		return nil
+	}
+	var p *PhysicalObject
+	if o, ok := MakeFromPrototype(u, vals); ok {
+		if p, ok = o.(*PhysicalObject); !ok {
+			mud.Log("[warn] prototype", vals["prototype"], "doesn't make a simple object")
+			return o
+		}
+	} else {
+		p = NewPhysicalObject(u)
+	}
+	p.id = id
+	ApplyFields(vals, p.setField)
+	p.register()
+	return p
+}
+
+func (n *NPC) fields() map[string]string {
+	fields := map[string]string{
+		"name": n.name,
+		"description": n.description,
+		"longDescription": n.longDescription,
+		"textHandles": strings.Join(n.textHandles, ","),
+		"visible": strconv.FormatBool(n.visible),
+		"carryable": strconv.FormatBool(n.carryable),
+	}
+	for key, value := range(n.Meta) {
+		if s, ok := value.(string); ok {
+			fields["meta." + key] = s
+		}
+	}
+	return fields
+}
+
+func (n *NPC) setField(name string, value string) {
+	switch name {
+	case "name":
+		n.name = value
+	case "description":
+		n.description = value
+	case "longDescription":
+		n.longDescription = value
+	case "textHandles":
+		n.textHandles = splitList(value)
+	case "visible":
+		n.visible = value == "true"
+	case "carryable":
+		n.carryable = value == "true"
+	default:
+		if strings.HasPrefix(name, "meta.") {
+			n.Meta[name[5:]] = value
+		}
+	}
+}
+
+// SetPrototypeName marks n as made by a prototype, taking its fields as the defaults
+func (n *NPC) SetPrototypeName(name string) {
+	n.prototype = name
+	n.defaults = n.fields()
+}
+
+func (n *NPC) PersistentValues() map[string]interface{} {
+	vals := make(map[string]interface{})
+	if n.id > 0 {
+		vals["id"] = strconv.Itoa(n.id)
+	}
+	vals["prototype"] = n.prototype
+	vals["fields"] = Overrides(n.fields(), n.defaults)
+	return vals
+}
+
+func (n *NPC) Save() string {
+	outID := n.universe.Store.SaveStructure("npc", n.PersistentValues())
+	if n.id == 0 {
+		n.id, _ = strconv.Atoi(outID)
+	}
+	return outID
+}
+
+func (n *NPC) DBFullName() string {
+	return fmt.Sprintf("npc:%d", n.id)
+}
+
+func LoadNPC(u *mud.Universe, id int) interface{} {
+	vals := u.Store.LoadStructure(mud.PersistentKeys["npc"],
+		mud.FieldJoin(":", "npc", strconv.Itoa(id)))
+	if _, ok := vals["id"]; !ok {
+		return nil
+	}
+	var n *NPC
+	if o, ok := MakeFromPrototype(u, vals); ok {
+		if n, ok = o.(*NPC); !ok {
+			mud.Log("[warn] prototype", vals["prototype"], "doesn't make a simple NPC")
+			return o
+		}
+	} else {
+		n = NewNPC(u)
+	}
+	n.id = id
+	ApplyFields(vals, n.setField)
+	return n
+}
@@ -0,0 +1,126 @@
+package simple
+
+import ("mud"
+	"mud/mudtest"
+	"reflect"
+	"testing")
+
+// testUniverse saves to memory rather than Redis
+func testUniverse() *mud.Universe {
+	return mud.NewUniverseWithClient(mudtest.NewMemoryClient())
+}
+
+func init() {
+	mud.Prototypes["test lamp"] = func(u *mud.Universe) mud.PhysicalObject {
+		lamp := NewPhysicalObject(u)
+		lamp.SetDescription("A brass lamp")
+		lamp.SetLightSource(true)
+		lamp.SetTextHandles("lamp")
+		return lamp
+	}
+}
+
+func TestPrototypeObjectSaved(t *testing.T) {
+	u := testUniverse()
+	o, _ := mud.MakePrototype(u, "test lamp")
+	lamp := o.(*PhysicalObject)
+	lamp.SetLit(true)
+	lamp.SetLongDescription("Its glass is sooty.")
+	lamp.Save()
+	if fields := lamp.PersistentValues()["fields"].([]string); len(fields) != 2 {
+		t.Errorf("only changed fields should be saved, got %v", fields)
+	}
+
+	loaded, ok := LoadObject(u, lamp.id).(*PhysicalObject)
+	if !ok || loaded.prototype != "test lamp" || !loaded.Lit() ||
+		!loaded.IsLightSource() || loaded.LongDescription() != "Its glass is sooty." ||
+		!reflect.DeepEqual(loaded.TextHandles(), []string{"lamp"}) {
+		t.Errorf("lamp loaded as %+v", loaded)
+	}
+}
+
+func TestObjectSaved(t *testing.T) {
+	u := testUniverse()
+	coin := NewPhysicalObject(u)
+	coin.SetDescription("A gold coin")
+	coin.SetWeight(2)
+	coin.SetValue(10)
+	coin.Save()
+
+	loaded, ok := LoadObject(u, coin.id).(*PhysicalObject)
+	if !ok || loaded.Description() != "A gold coin" || loaded.Weight() != 2 ||
+		loaded.Value() != 10 {
+		t.Errorf("coin loaded as %+v", loaded)
+	}
+	if loaded.TextHandles() != nil || loaded.Slots() != nil {
+		t.Errorf("empty lists should load as nil, got %q and %q",
+			loaded.TextHandles(), loaded.Slots())
+	}
+}
+
+func TestContainerSaved(t *testing.T) {
+	u := testUniverse()
+	chest := NewContainer(u, 10)
+	chest.SetDescription("A chest")
+	chest.SetContainerState(mud.DoorLocked)
+	chest.SetKeyHandle("iron key")
+	pouch := NewContainer(u, 2)
+	pouch.SetDescription("A pouch")
+	coin := NewPhysicalObject(u)
+	coin.SetDescription("A gold coin")
+	pouch.AddContent(coin)
+	chest.AddContent(pouch)
+	coin.Save()
+	pouch.Save()
+	chest.Save()
+
+	loaded, ok := LoadContainer(u, chest.id).(*Container)
+	if !ok || loaded.Description() != "A chest" || loaded.Capacity() != 10 ||
+		loaded.ContainerState() != mud.DoorLocked || loaded.KeyHandle() != "iron key" {
+		t.Fatalf("chest loaded as %+v", loaded)
+	}
+	contents := loaded.Contents()
+	if len(contents) != 1 {
+		t.Fatalf("chest holds %d things", len(contents))
+	}
+	inner, ok := contents[0].(*Container)
+	if !ok || inner.Description() != "A pouch" || len(inner.Contents()) != 1 ||
+		inner.Contents()[0].Description() != "A gold coin" {
+		t.Errorf("pouch loaded as %+v", contents[0])
+	}
+}
+
+func saved(u *mud.Universe, p *PhysicalObject) bool {
+	for _, persister := range(u.Persistents()) {
+		if s, ok := persister.(objectSaver); ok && s.object == p {
+			return true
+		}
+	}
+	return false
+}
+
+func TestObjectsSavedOncePlaced(t *testing.T) {
+	NewPhysicalObject(nil).Destroyed()
+
+	u := testUniverse()
+	stock := NewPhysicalObject(u)
+	if saved(u, stock) {
+		t.Error("an object nowhere in the world shouldn't be saved")
+	}
+	bag := NewContainer(u, 5)
+	coin := NewPhysicalObject(u)
+	bag.AddContent(coin)
+	bag.SetRoom(mud.NewRoom(u, 0, "A vault."))
+	if !saved(u, bag.PhysicalObject) || !saved(u, coin) {
+		t.Error("a placed container and its contents should be saved")
+	}
+	purse := NewPhysicalObject(u)
+	bag.AddContent(purse)
+	if !saved(u, purse) {
+		t.Error("what is put in a saved container should be saved")
+	}
+	bag.Destroyed()
+	if saved(u, bag.PhysicalObject) {
+		t.Error("a destroyed object shouldn't be saved")
+	}
+}
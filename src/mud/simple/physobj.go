@@ -1,6 +1,7 @@
 package simple
 
-import "mud"
+import ("mud"
+	"strings")
 
 type SimpleTimeHandler func(int, *PhysicalObject)
 
@@ -14,7 +15,25 @@ type PhysicalObject struct {
 	visible bool
 	carryable bool
 	description string
+	longDescription string
+	lightSource bool
+	lit bool
 	textHandles []string
+	id int
+	prototype string
+	defaults map[string]string
+	saveAs mud.Persister
+	// whether the universe saves the object, see register
+	registered bool
+	weight int
+	bulk int
+	value mud.Currency
+	slots []string
+	modifiers map[string]int
+	effects map[string]mud.Effect
+	stackable bool
+	count int
+	durability *mud.Durability
 	universe *mud.Universe
 }
 
@@ -23,16 +42,112 @@ func (p *PhysicalObject) UpdateTimeLoop() {
 	for { p.timeHandler(<- p.tPing, p) }
 }
 
-func (p *PhysicalObject) SetRoom(r *mud.Room) { p.room = r }
+// SetRoom places p in r, after which the universe keeps it saved
+func (p *PhysicalObject) SetRoom(r *mud.Room) {
+	p.room = r
+	if r != nil {
+		p.register()
+	}
+}
 func (p PhysicalObject) Room() *mud.Room { return p.room }
 
 func (p PhysicalObject) Visible() bool { return p.visible }
 func (p PhysicalObject) Carryable() bool { return p.carryable }
 func (p PhysicalObject) TextHandles() []string { return p.textHandles }
-func (p PhysicalObject) Description() string { return p.description }
+func (p PhysicalObject) Description() string {
+	description := p.description
+	if p.durability != nil {
+		description += " (" + p.durability.ConditionName() + ")"
+	}
+	if p.lightSource && p.lit {
+		return description + " (lit)"
+	}
+	return description
+}
 func (p *PhysicalObject) SetDescription(d string) { p.description = d }
+func (p PhysicalObject) LongDescription() string { return p.longDescription }
+func (p *PhysicalObject) SetLongDescription(d string) { p.longDescription = d }
 func (p *PhysicalObject) SetCarryable(c bool) { p.carryable = c}
 func (p *PhysicalObject) SetVisible(v bool) { p.visible = v }
+func (p PhysicalObject) IsLightSource() bool { return p.lightSource }
+func (p PhysicalObject) Lit() bool { return p.lit }
+func (p *PhysicalObject) SetLit(l bool) { p.lit = l }
+
+// SetLightSource lets the object be lit, like a lamp or torch
+func (p *PhysicalObject) SetLightSource(l bool) { p.lightSource = l }
+func (p PhysicalObject) Weight() int { return p.weight }
+func (p *PhysicalObject) SetWeight(w int) { p.weight = w }
+func (p PhysicalObject) Bulk() int { return p.bulk }
+func (p *PhysicalObject) SetBulk(b int) { p.bulk = b }
+func (p PhysicalObject) Value() mud.Currency { return p.value }
+func (p *PhysicalObject) SetValue(v mud.Currency) { p.value = v }
+
+// SetSlots lets the object be worn, wielded or held in the named mud.EquipmentSlots
+func (p *PhysicalObject) SetSlots(slots... string) { p.slots = slots }
+func (p PhysicalObject) Slots() []string { return p.slots }
+func (p PhysicalObject) Modifiers() map[string]int { return p.modifiers }
+
+// SetEffect lets the object be eaten, drunk or used (the verb) with effect
+func (p *PhysicalObject) SetEffect(verb string, effect mud.Effect) {
+	if p.effects == nil {
+		p.effects = make(map[string]mud.Effect)
+	}
+	p.effects[verb] = effect
+}
+
+func (p *PhysicalObject) Consume(player *mud.Player, verb string) (mud.Effect, bool) {
+	effect, ok := p.effects[verb]
+	return effect, ok
+}
+
+// SetModifier changes the wearer's stat by n while the object is equipped
+func (p *PhysicalObject) SetModifier(stat string, n int) {
+	if p.modifiers == nil {
+		p.modifiers = make(map[string]int)
+	}
+	p.modifiers[stat] = n
+}
+// SetStackable lets alike objects gather into one with a count
+func (p *PhysicalObject) SetStackable(s bool) { p.stackable = s }
+func (p PhysicalObject) Count() int { return p.count }
+func (p *PhysicalObject) SetCount(n int) { p.count = n }
+
+// StackKey is the same for objects from one prototype with the same fields
+func (p *PhysicalObject) StackKey() string {
+	if !p.stackable {
+		return ""
+	}
+	fields := p.fields()
+	delete(fields, "count")
+	return p.prototype + ":" + strings.Join(Overrides(fields, p.defaults), ",")
+}
+
+// Split takes n off the stack as a new object, alike but for its count
+func (p *PhysicalObject) Split(n int) mud.Stackable {
+	part := NewPhysicalObject(p.universe)
+	part.prototype, part.defaults = p.prototype, p.defaults
+	if p.durability != nil {
+		d := *p.durability
+		part.durability = &d
+	}
+	for name, value := range(p.fields()) { part.setField(name, value) }
+	part.effects = p.effects
+	part.count = n
+	p.count -= n
+	if p.registered {
+		part.register()
+	}
+	return part
+}
+
+// SetDurability lets the object wear out or decay, starting at its best
+func (p *PhysicalObject) SetDurability(d mud.Durability) {
+	d.Condition = d.Max
+	p.durability = &d
+}
+func (p *PhysicalObject) Durability() *mud.Durability { return p.durability }
+
+func (p *PhysicalObject) PrototypeName() string { return p.prototype }
 func (p *PhysicalObject) SetUniverse(u *mud.Universe) { p.universe = u }
 func (p *PhysicalObject) SetTextHandles(handles... string) {
 	p.textHandles = handles
@@ -46,6 +161,9 @@ func (p *PhysicalObject) SetTimeHandler(handler SimpleTimeHandler) {
 
 func NewPhysicalObject(u *mud.Universe) *PhysicalObject {
 	p := new(PhysicalObject)
+	p.universe = u
+	p.weight, p.bulk, p.count = 1, 1, 1
+	p.saveAs = p
 	go p.UpdateTimeLoop()
 	return p
-}
\ No newline at end of file
+}
@@ -0,0 +1,202 @@
+package mud
+
+import ("bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings")
+
+/*
+ Social is a predefined emote (smile, bow, hug) with message
+ variants for the actor, the target and any bystanders.
+
+ Messages are keyed by the fields in socialFields. In message text,
+ $n is replaced with the actor's name and $N with the target's name.
+ */
+type Social struct {
+	Name string
+	Messages map[string]string
+}
+
+var socialFields = map[string]bool{
+	// no target
+	"actor": true,
+	"others": true,
+	// targeting someone else
+	"target-actor": true,
+	"target-target": true,
+	"target-others": true,
+	// targeting yourself
+	"self-actor": true,
+	"self-others": true,
+	// target not present
+	"notfound": true,
+}
+
+type SocialStimulus struct {
+	Stimulus
+	social *Social
+	actor *Player
+	target PhysicalObject
+}
+
+func init() {
+	PlayerPerceptions["social"] = doesPerceiveSocial
+}
+
+func doesPerceiveSocial(p Player, s Stimulus) bool { return true }
+
+func (s SocialStimulus) StimType() string { return "social" }
+func (s SocialStimulus) Social() *Social { return s.social }
+func (s SocialStimulus) Actor() *Player { return s.actor }
+func (s SocialStimulus) Target() PhysicalObject { return s.target }
+
+// TargetsSelf is true when the actor used the social on themselves
+func (s SocialStimulus) TargetsSelf() bool {
+	return s.target != nil && interface{}(s.target) == interface{}(s.actor)
+}
+
+func (s SocialStimulus) Description(p Perceiver) string {
+	isActor := interface{}(p) == interface{}(s.actor)
+	var field string
+	switch {
+	case s.target == nil && isActor:
+		field = "actor"
+	case s.target == nil:
+		field = "others"
+	case s.TargetsSelf() && isActor:
+		field = "self-actor"
+	case s.TargetsSelf():
+		field = "self-others"
+	case isActor:
+		field = "target-actor"
+	case interface{}(p) == interface{}(s.target):
+		field = "target-target"
+	default:
+		field = "target-others"
+	}
+	return s.social.Format(field, s.actor, s.target)
+}
+
+// Format fills in the message for field, or returns "" if the social
+// doesn't define it.
+func (s *Social) Format(field string, actor *Player, target PhysicalObject) string {
+	msg, ok := s.Messages[field]
+	if !ok {
+		return ""
+	}
+	msg = strings.Replace(msg, "$n", actor.name, -1)
+	if target != nil {
+		msg = strings.Replace(msg, "$N", objectName(target), -1)
+	}
+	return msg + "\n"
+}
+
+func objectName(o PhysicalObject) string {
+	if t, ok := o.(Talker); ok && t.Name() != "" {
+		return t.Name()
+	}
+	return o.Description()
+}
+
+func socialCommand(s *Social) Command {
+	return func(p *Player, args []string) {
+		stim := SocialStimulus{social: s, actor: p}
+		if len(args) > 0 {
+			if _, ok := s.Messages["target-actor"]; !ok {
+				p.WriteString("You can't " + s.Name + " at anyone.\n")
+				return
+			}
+			ident := strings.ToLower(args[0])
+			target, ok := p.PerceiveList(LookContext)[ident]
+			if !ok {
+				if msg := s.Format("notfound", p, nil); msg != "" {
+					p.WriteString(msg)
+				} else {
+					p.WriteString(ident + " not seen.\n")
+				}
+				return
+			}
+			stim.target = target
+		}
+		p.room.Broadcast(stim)
+	}
+}
+
+/*
+ ParseSocials reads socials from r. The format is a series of
+ stanzas, each headed by the social's name in square brackets and
+ followed by "field: message" lines. Blank lines and lines starting
+ with # are ignored.
+
+	[smile]
+	actor: You smile happily.
+	others: $n smiles happily.
+ */
+func ParseSocials(r io.Reader) (map[string]*Social, error) {
+	socials := make(map[string]*Social)
+	var current *Social
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.ToLower(strings.TrimSpace(line[1:len(line)-1]))
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty social name", lineNo)
+			}
+			if _, exists := socials[name]; exists {
+				return nil, fmt.Errorf("line %d: social '%s' defined twice",
+					lineNo, name)
+			}
+			current = &Social{Name: name, Messages: make(map[string]string)}
+			socials[name] = current
+		default:
+			if current == nil {
+				return nil, fmt.Errorf("line %d: message outside of a social",
+					lineNo)
+			}
+			parts := strings.SplitN(line, ":", 2)
+			field := strings.TrimSpace(parts[0])
+			if len(parts) != 2 || !socialFields[field] {
+				return nil, fmt.Errorf("line %d: unrecognized field '%s'",
+					lineNo, field)
+			}
+			current.Messages[field] = strings.TrimSpace(parts[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for name, s := range socials {
+		if _, ok := s.Messages["actor"]; !ok {
+			return nil, fmt.Errorf("social '%s' has no actor message", name)
+		}
+	}
+	return socials, nil
+}
+
+func LoadSocials(filename string) (map[string]*Social, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseSocials(f)
+}
+
+/*
+ RegisterSocials adds each social to SocialCommands. Socials never
+ replace a global command of the same name, nor one given in a room.
+ */
+func RegisterSocials(socials map[string]*Social) {
+	for name, s := range socials {
+		if _, exists := GlobalCommands[name]; exists {
+			Log("[WARN] social", name, "shadows a command, skipping")
+			continue
+		}
+		SocialCommands[name] = socialCommand(s)
+	}
+}
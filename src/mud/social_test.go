@@ -0,0 +1,84 @@
+package mud
+
+import ("strings"
+	"testing")
+
+const testSocials = `
+# a comment
+[smile]
+actor: You smile.
+others: $n smiles.
+target-actor: You smile at $N.
+
+[Bow]
+actor: You bow.
+`
+
+func TestParseSocials(t *testing.T) {
+	socials, err := ParseSocials(strings.NewReader(testSocials))
+	if err != nil {
+		t.Fatalf("ParseSocials returned error: %s", err)
+	}
+	if len(socials) != 2 {
+		t.Errorf("expected 2 socials, got %d", len(socials))
+	}
+	if _, ok := socials["bow"]; !ok {
+		t.Errorf("social names should be lowercased")
+	}
+	if msg := socials["smile"].Messages["target-actor"]; msg != "You smile at $N." {
+		t.Errorf("unexpected target-actor message '%s'", msg)
+	}
+}
+
+func TestParseSocialsErrorLine(t *testing.T) {
+	_, err := ParseSocials(strings.NewReader("[smile]\nactor: x\nbogus: y\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("expected error on line 3, got %v", err)
+	}
+	_, err = ParseSocials(strings.NewReader("[smile]\nothers: x\n"))
+	if err == nil {
+		t.Errorf("social without actor message should not parse")
+	}
+}
+
+func TestSocialFormat(t *testing.T) {
+	socials, _ := ParseSocials(strings.NewReader(testSocials))
+	actor := &Player{name: "Alice"}
+	target := &Player{name: "Bob"}
+	msg := socials["smile"].Format("target-actor", actor, target)
+	if msg != "You smile at Bob.\n" {
+		t.Errorf("unexpected formatted message '%s'", msg)
+	}
+	if socials["bow"].Format("others", actor, nil) != "" {
+		t.Errorf("missing fields should format to empty string")
+	}
+}
+
+type testShop struct {
+	testThing
+	haggled bool
+}
+
+func (s *testShop) Commands() map[string]Command {
+	return map[string]Command{"haggle": func(p *Player, args []string) { s.haggled = true }}
+}
+
+func TestSocialsDontShadowRoomCommands(t *testing.T) {
+	socials, _ := ParseSocials(strings.NewReader(testSocials + "[haggle]\nactor: You haggle.\n"))
+	RegisterSocials(socials)
+	defer func() {
+		for name := range(socials) { delete(SocialCommands, name) }
+	}()
+	r := NewRoom(testUniverse(), 0, "A market.")
+	shop := &testShop{testThing: testThing{handle: "stall"}}
+	r.AddChild(shop)
+	p, _ := testPlayer(r)
+
+	p.execCommand("haggle")
+	if !shop.haggled {
+		t.Error("the stall's haggle should be used, not the social")
+	}
+	if _, global := GlobalCommands["smile"]; global || SocialCommands["smile"] == nil {
+		t.Error("socials should be registered apart from global commands")
+	}
+}
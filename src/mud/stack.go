@@ -0,0 +1,178 @@
+package mud
+
+import ("fmt"
+	"strconv"
+	"strings")
+
+func init() {
+	GlobalCommands["give"] = give
+
+	PlayerPerceptions["give"] = doesPerceiveGive
+}
+
+/*
+ Stackable is implemented by objects which gather into one object with
+ a count, like peaches or potions. Objects with the same StackKey
+ stack; an empty key means the object doesn't. Split takes n off the
+ stack as a new object, leaving the rest.
+ */
+type Stackable interface {
+	PhysicalObject
+	StackKey() string
+	Count() int
+	SetCount(n int)
+	Split(n int) Stackable
+}
+
+// CountOf is how many o is: a stack's count, or else one
+func CountOf(o PhysicalObject) int {
+	if s, ok := o.(Stackable); ok && s.StackKey() != "" {
+		return s.Count()
+	}
+	return 1
+}
+
+var articles = []string{ "A(n) ", "An ", "A ", "The " }
+
+/*
+ StackDescription describes o as inventories and rooms list it, with
+ the count of a stack before its description, e.g. "(x5) ripe peach".
+ */
+func StackDescription(o PhysicalObject) string {
+	n := CountOf(o)
+	if n == 1 {
+		return o.Description()
+	}
+	text := o.Description()
+	for _, article := range(articles) {
+		if strings.HasPrefix(text, article) {
+			text = text[len(article):]
+			break
+		}
+	}
+	return fmt.Sprintf("(x%d) %s", n, text)
+}
+
+/*
+ MergeStack adds o to a stack like it among objs, destroying o. It is
+ false if o doesn't stack with any of them, and should be added as it
+ is.
+ */
+func MergeStack(u *Universe, objs []PhysicalObject, o PhysicalObject) bool {
+	s, ok := o.(Stackable)
+	if !ok || s.StackKey() == "" {
+		return false
+	}
+	for _, other := range(objs) {
+		if stack, ok := other.(Stackable); ok && other != o && stack.StackKey() == s.StackKey() {
+			stack.SetCount(stack.Count() + s.Count())
+			Destroy(u, o)
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ SplitStack takes n of o: o itself if n is 0 or all of it, or a new
+ stack split from it. It returns a message if there aren't n.
+ */
+func SplitStack(o PhysicalObject, n int) (PhysicalObject, string) {
+	switch count := CountOf(o); {
+	case n == 0 || n == count:
+		return o, ""
+	case n > count:
+		return nil, fmt.Sprintf("You only have %d.\n", count)
+	}
+	return o.(Stackable).Split(n), ""
+}
+
+// splitCount reads a count before an object's name, as in "drop 3 peach"
+func splitCount(args []string) (int, []string) {
+	if len(args) > 1 {
+		if n, err := strconv.Atoi(args[0]); err == nil && n > 0 {
+			return n, args[1:]
+		}
+	}
+	return 0, args
+}
+
+type GiveStimulus struct {
+	Stimulus
+	giver *Player
+	receiver *Player
+	obj PhysicalObject
+}
+
+func (s GiveStimulus) StimType() string { return "give" }
+func (s GiveStimulus) Description(p Perceiver) string {
+	what := strings.ToLower(StackDescription(s.obj))
+	if playerReceiver, ok := p.(*Player); ok {
+		switch playerReceiver.id {
+		case s.giver.id:
+			return "You give " + what + " to " + s.receiver.name + ".\n"
+		case s.receiver.id:
+			return s.giver.name + " gives you " + what + ".\n"
+		}
+	}
+	return s.giver.name + " gives " + what + " to " + s.receiver.name + ".\n"
+}
+
+func doesPerceiveGive(p Player, s Stimulus) bool { return true }
+
+type GiveAction struct {
+	InterObjectAction
+	player *Player
+	count int
+	what string
+	to string
+}
+
+func (a GiveAction) Targets() []PhysicalObject { return []PhysicalObject{} }
+func (a GiveAction) Source() PhysicalObject { return a.player }
+func (a GiveAction) Exec() {
+	player := a.player
+	o, ok := player.PerceiveList(InvContext)[a.what]
+	if !ok {
+		player.WriteString(a.what + " not in your inventory.\n")
+		return
+	}
+	target, ok := player.PerceiveList(LookContext)[a.to]
+	receiver, isPlayer := target.(*Player)
+	switch {
+	case !ok:
+		player.WriteString("You see no " + a.to + " here.\n")
+		return
+	case !isPlayer:
+		player.WriteString("You can't give things to that.\n")
+		return
+	case receiver == player:
+		player.WriteString("You already have it.\n")
+		return
+	}
+	part, msg := SplitStack(o, a.count)
+	if msg != "" {
+		player.WriteString(msg)
+		return
+	}
+	if !receiver.CanCarry(part) {
+		if part != o {
+			MergeStack(player.Universe, []PhysicalObject{o}, part)
+		}
+		player.WriteString(receiver.name + " can't carry that.\n")
+		return
+	}
+	player.inventory.Remove(part)
+	player.room.Broadcast(GiveStimulus{giver: player, receiver: receiver, obj: part})
+	receiver.Add(part)
+}
+
+func give(p *Player, args []string) {
+	n, args := splitCount(args)
+	what, to, ok := splitOn(args, "to")
+	if !ok {
+		p.WriteString("Give usage: give [number] [object] to [player].\n")
+		return
+	}
+	p.room.Act(GiveAction{player: p, count: n, what: what, to: to})
+}
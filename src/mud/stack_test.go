@@ -0,0 +1,68 @@
+package mud
+
+import "testing"
+
+type testStack struct {
+	testObject
+	room *Room
+	kind string
+	count int
+}
+
+func (s *testStack) SetRoom(r *Room) { s.room = r }
+func (s *testStack) Room() *Room { return s.room }
+func (s *testStack) StackKey() string { return s.kind }
+func (s *testStack) TextHandles() []string { return []string{s.kind} }
+func (s *testStack) Count() int { return s.count }
+func (s *testStack) SetCount(n int) { s.count = n }
+func (s *testStack) Split(n int) Stackable {
+	s.count -= n
+	return &testStack{testObject: s.testObject, kind: s.kind, count: n}
+}
+
+func TestStacks(t *testing.T) {
+	u := &Universe{children: NewFlexContainer("Persistents", "TimeListeners")}
+	peaches := &testStack{testObject{name: "A(n) ripe peach"}, nil, "ripe peach", 5}
+	more := &testStack{testObject{name: "A(n) ripe peach"}, nil, "ripe peach", 2}
+	pears := &testStack{testObject{name: "A(n) ripe pear"}, nil, "ripe pear", 1}
+
+	if text := StackDescription(peaches); text != "(x5) ripe peach" {
+		t.Errorf("stack described as %q", text)
+	}
+	if text := StackDescription(pears); text != "A(n) ripe pear" {
+		t.Errorf("single object described as %q", text)
+	}
+	if WeightOf(peaches) != 5 || Load(peaches) != 5 {
+		t.Error("a stack should weigh and load as much as its count")
+	}
+
+	objs := []PhysicalObject{pears, peaches}
+	if !MergeStack(u, objs, more) || peaches.count != 7 {
+		t.Errorf("peaches should merge into a stack of 7, got %d", peaches.count)
+	}
+	if MergeStack(u, []PhysicalObject{pears}, &testStack{testObject{}, nil, "", 1}) {
+		t.Error("objects without a stack key shouldn't merge")
+	}
+
+	if _, msg := SplitStack(peaches, 8); msg == "" {
+		t.Error("shouldn't split more than the stack holds")
+	}
+	part, _ := SplitStack(peaches, 3)
+	if CountOf(part) != 3 || peaches.count != 4 {
+		t.Errorf("split should leave 3 and 4, got %d and %d", CountOf(part), peaches.count)
+	}
+	if whole, _ := SplitStack(peaches, 4); whole != PhysicalObject(peaches) {
+		t.Error("taking the whole stack should take the stack itself")
+	}
+
+	if n := countInstances(objs, "ripe peach"); n != 4 {
+		t.Errorf("resets should count each of a stack, got %d", n)
+	}
+
+	if n, args := splitCount([]string{"3", "peach"}); n != 3 || args[0] != "peach" {
+		t.Errorf("drop 3 peach read as %d %v", n, args)
+	}
+	if n, _ := splitCount([]string{"7"}); n != 0 {
+		t.Error("a lone number is an object name, not a count")
+	}
+}
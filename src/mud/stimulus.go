@@ -37,13 +37,21 @@ type PlayerDropStimulus struct {
 
 func (s PlayerEnterStimulus) StimType() string { return "enter" }
 func (s PlayerEnterStimulus) Description(p Perceiver) string {
+	if s.from != "" {
+		return s.player.name + " " + s.from + ".\n"
+	}
 	return s.player.name + " has entered the room.\n"
 }
+func (s PlayerEnterStimulus) From() string { return s.from }
 
 func (s PlayerLeaveStimulus) StimType() string { return "exit" }
 func (s PlayerLeaveStimulus) Description(p Perceiver) string {
+	if s.to != "" {
+		return s.player.name + " " + s.to + ".\n"
+	}
 	return s.player.name + " has left the room.\n"
 }
+func (s PlayerLeaveStimulus) To() string { return s.to }
 
 func (s TalkerSayStimulus) StimType() string { return "say" }
 func (s TalkerSayStimulus) Description(p Perceiver) string {
@@ -51,6 +59,9 @@ func (s TalkerSayStimulus) Description(p Perceiver) string {
 	if ok && s.talker.ID() == playerReceiver.id {
 		return "You say \"" + s.text + "\"\n"
 	} 
+	if ok && playerReceiver.room != nil && playerReceiver.room.IsDark() {
+		return "Someone says \"" + s.text + "\".\n"
+	}
 	return s.talker.Name() + " said " + "\"" + s.text + "\".\n"
 }
 func (s TalkerSayStimulus) Text() string { return s.text }
@@ -60,16 +71,16 @@ func (s PlayerPickupStimulus) StimType() string { return "take" }
 func (s PlayerPickupStimulus) Description(p Perceiver) string {
 	playerReceiver, ok := p.(*Player)
 	if ok && s.player.ID() == playerReceiver.id {
-		return "You picked up \"" + s.obj.Description() + "\"\n"
+		return "You picked up \"" + StackDescription(s.obj) + "\"\n"
 	} 
-	return s.player.name + " picked up " + "\"" + s.obj.Description() + "\".\n"
+	return s.player.name + " picked up " + "\"" + StackDescription(s.obj) + "\".\n"
 }
 
 func (s PlayerDropStimulus) StimType() string { return "drop" }
 func (s PlayerDropStimulus) Description(p Perceiver) string {
 	playerReceiver, ok := p.(*Player)
 	if ok && s.player.ID() == playerReceiver.id {
-		return "You dropped \"" + s.obj.Description() + "\"\n"
+		return "You dropped \"" + StackDescription(s.obj) + "\"\n"
 	} 
-	return s.player.name + " dropped " + "\"" + s.obj.Description() + "\".\n"
+	return s.player.name + " dropped " + "\"" + StackDescription(s.obj) + "\".\n"
 }
\ No newline at end of file
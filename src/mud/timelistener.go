@@ -23,4 +23,26 @@ func init() {
 
 type TimeListener interface {
 	Ping() chan int
-}
\ No newline at end of file
+}
+
+/*
+ StoppableTimeListener is a TimeListener whose Stopped channel closes
+ when it stops listening, such as when it is destroyed, so that a
+ heartbeat already on its way isn't left waiting for it.
+ */
+type StoppableTimeListener interface {
+	TimeListener
+	Stopped() chan bool
+}
+
+// ping sends n to l, unless l has stopped listening
+func ping(l TimeListener, n int) {
+	var stopped chan bool
+	if s, ok := l.(StoppableTimeListener); ok {
+		stopped = s.Stopped()
+	}
+	select {
+	case l.Ping() <- n:
+	case <- stopped:
+	}
+}
@@ -0,0 +1,27 @@
+package mud
+
+import ("testing"
+	"time")
+
+type testStoppable struct {
+	ping chan int
+	stopped chan bool
+}
+
+func (l *testStoppable) Ping() chan int { return l.ping }
+func (l *testStoppable) Stopped() chan bool { return l.stopped }
+
+func TestPingSkipsStoppedListeners(t *testing.T) {
+	l := &testStoppable{ping: make(chan int), stopped: make(chan bool)}
+	go func() { <- l.ping }()
+	ping(l, 1)
+
+	close(l.stopped)
+	finished := make(chan bool)
+	go func() { ping(l, 2); finished <- true }()
+	select {
+	case <- finished:
+	case <- time.After(time.Second):
+		t.Fatal("a stopped listener shouldn't hold up the heartbeat")
+	}
+}
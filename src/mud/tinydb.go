@@ -40,6 +40,11 @@ func (t *TinyDB) RedisSet(k string, v interface{}) {
 		for _,p := range(ty) {
 			t.dbConn.Sadd(k, []byte(p.DBFullName()))
 		}
+	case []string:
+		t.dbConn.Del(k)
+		for _,s := range(ty) {
+			t.dbConn.Sadd(k, []byte(s))
+		}
 	default:
 		panic(fmt.Sprintf("Unrecognized interface %T in RedisSet",ty))
 	}
@@ -109,6 +114,22 @@ func (t *TinyDB) SaveStructure(className string, vals map[string]interface{}) st
 	return returnId
 }
 
+// DeleteStructure deletes the keys of className's record id
+func (t *TinyDB) DeleteStructure(className string, id string, keys []string) {
+	for _, key := range(keys) {
+		t.dbConn.Del(FieldJoin(":", className, id, key))
+	}
+}
+
+// RaiseIDCounter makes sure new IDs for className come after id
+func (t *TinyDB) RaiseIDCounter(className string, id int) {
+	key := FieldJoin(":",className,"idCounter")
+	current, _ := t.RedisGet(key)
+	if n, _ := strconv.Atoi(current); n < id {
+		t.RedisSet(key, strconv.Itoa(id))
+	}
+}
+
 func (t *TinyDB) Flush() {
 	t.dbConn.Flushdb()
 }
\ No newline at end of file
@@ -8,6 +8,12 @@ type MakeHandler func (*Universe, *Player, []string)
 type Universe struct {
 	Players map[int]*Player
 	Rooms map[int]*Room
+	Zones map[int]*Zone
+	// StartRoom is where new players are placed
+	StartRoom *Room
+	Clock *GameClock
+	Weather *WeatherSystem
+	Journal *Journal
 	children *FlexContainer
 	Maker MakeHandler
 	Store *TinyDB
@@ -15,18 +21,23 @@ type Universe struct {
 }
 
 func NewUniverse(dbNo int) *Universe {
-	u := new(Universe)
-	u.Players = make(map[int]*Player)
-	u.Rooms = make(map[int]*Room)
-	u.children = NewFlexContainer("Persistents", "TimeListeners")
 	spec := redis.DefaultSpec().Db(dbNo)
 	client, err := redis.NewSynchClientWithSpec(spec)
 	if(err != nil) {
 		panic(err)
-	} else {
-		u.dbConn = client
-		u.Store = NewTinyDB(client)
 	}
+	return NewUniverseWithClient(client)
+}
+
+// NewUniverseWithClient is a universe saving through client
+func NewUniverseWithClient(client redis.Client) *Universe {
+	u := new(Universe)
+	u.Players = make(map[int]*Player)
+	u.Rooms = make(map[int]*Room)
+	u.Zones = make(map[int]*Zone)
+	u.children = NewFlexContainer("Persistents", "TimeListeners")
+	u.dbConn = client
+	u.Store = NewTinyDB(client)
 	return u
 }
 
@@ -63,10 +74,14 @@ func (u *Universe) Add(o interface{}) {
 	u.children.Add(o)
 }
 
+func (u *Universe) Remove(o interface{}) {
+	u.children.Remove(o)
+}
+
 func (u *Universe) HeartbeatLoop(speedupFactor float64) {
 	for n:=0 ; ; n++ {
 		for _, l := range(u.TimeListeners()) {
-			l.Ping() <- n
+			ping(l, n)
 		}
 		time.Sleep(time.Duration(int(1000000/speedupFactor))*time.Nanosecond)
 	}
@@ -0,0 +1,8 @@
+package mud
+
+import "mud/mudtest"
+
+// testUniverse saves to memory rather than Redis
+func testUniverse() *Universe {
+	return NewUniverseWithClient(mudtest.NewMemoryClient())
+}
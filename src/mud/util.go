@@ -1,6 +1,8 @@
 package mud
 
-import "regexp"
+import ("regexp"
+	"sort"
+	"strings")
 
 func SplitCommandString(cmd string) []string {
 	re, _ := regexp.Compile(`(\S+)|(['"][^'"]+['"])`)
@@ -19,4 +21,24 @@ func PlayersAsPhysObjSlice(ps map[int]*Player) []PhysicalObject {
 		n++
 	}
 	return physObjs
-}
\ No newline at end of file
+}
+// propertyStrings flattens a property map to sorted "key=value"
+// strings, for persisting as a set.
+func propertyStrings(props map[string]string) []string {
+	strs := []string{}
+	for k, v := range(props) {
+		strs = append(strs, k + "=" + v)
+	}
+	sort.Strings(strs)
+	return strs
+}
+
+func parsePropertyStrings(strs []string) map[string]string {
+	props := make(map[string]string)
+	for _, s := range(strs) {
+		if kv := strings.SplitN(s, "=", 2); len(kv) == 2 {
+			props[kv[0]] = kv[1]
+		}
+	}
+	return props
+}
@@ -0,0 +1,189 @@
+package mud
+
+import ("math/rand"
+	"sync")
+
+func init() {
+	GlobalCommands["weather"] = weatherCommand
+
+	PlayerPerceptions["weather"] = doesPerceiveWeather
+
+	DescribeHooks = append(DescribeHooks, describeWeather)
+}
+
+// Kinds of weather
+const (
+	Clear = "clear"
+	Rain = "rain"
+	Storm = "storm"
+	Snow = "snow"
+)
+
+var weatherKinds = []string{ Clear, Rain, Storm, Snow }
+
+// Percentage chance of each kind of weather, by season
+var seasonWeather = map[string]map[string]int{
+	"spring": {Clear: 50, Rain: 35, Storm: 15, Snow: 0},
+	"summer": {Clear: 65, Rain: 20, Storm: 15, Snow: 0},
+	"autumn": {Clear: 45, Rain: 40, Storm: 10, Snow: 5},
+	"winter": {Clear: 45, Rain: 10, Storm: 5, Snow: 40},
+}
+
+// How many times faster than usual things grow outdoors in each weather
+var weatherGrowth = map[string]float64{
+	Clear: 1.0, Rain: 1.5, Storm: 1.25, Snow: 0.25,
+}
+
+var weatherLooks = map[string]string{
+	Clear: "The sky is clear.",
+	Rain: "Rain is falling steadily.",
+	Storm: "A storm rages, thunder rolling overhead.",
+	Snow: "Snow is falling softly.",
+}
+
+var weatherChanges = map[string]string{
+	Clear: "The weather clears.",
+	Rain: "It starts to rain.",
+	Storm: "A storm breaks overhead.",
+	Snow: "Snow begins to fall.",
+}
+
+// Chance in an hour that a zone's weather is rolled again
+const weatherChangeOdds = 4
+
+/*
+ ChooseWeather picks the weather for a season given a roll from 0 to
+ 99, so that each kind comes up as often as seasonWeather says.
+ */
+func ChooseWeather(season string, roll int) string {
+	for _, kind := range(weatherKinds) {
+		roll -= seasonWeather[season][kind]
+		if roll < 0 {
+			return kind
+		}
+	}
+	return Clear
+}
+
+/*
+ WeatherSystem keeps the weather of each zone, and of rooms in no zone,
+ changing it as the game hours pass. Only outdoor rooms have weather.
+ */
+type WeatherSystem struct {
+	universe *Universe
+	lock sync.RWMutex
+	current map[*Zone]string
+}
+
+// StartWeather rolls the weather everywhere and keeps it changing
+// with u's GameClock, which must be loaded first.
+func StartWeather(u *Universe) *WeatherSystem {
+	w := new(WeatherSystem)
+	w.universe = u
+	w.current = make(map[*Zone]string)
+	season := u.Clock.Now().Season()
+	w.current[nil] = ChooseWeather(season, rand.Intn(100))
+	for _, z := range(u.Zones) {
+		w.current[z] = ChooseWeather(season, rand.Intn(100))
+	}
+	u.Weather = w
+	u.Clock.OnHour(w.hourPassed)
+	return w
+}
+
+func (w *WeatherSystem) hourPassed(now GameTime) {
+	zones := []*Zone{nil}
+	for _, z := range(w.universe.Zones) { zones = append(zones, z) }
+	for _, z := range(zones) {
+		if rand.Intn(weatherChangeOdds) == 0 {
+			w.Set(z, ChooseWeather(now.Season(), rand.Intn(100)))
+		}
+	}
+}
+
+// In is the weather of z; a nil zone is rooms which have no zone.
+func (w *WeatherSystem) In(z *Zone) string {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	if kind, ok := w.current[z]; ok {
+		return kind
+	}
+	return w.current[nil]
+}
+
+// Set changes the weather of z, telling its outdoor rooms if it differs.
+// Rooms too busy to hear it just miss the news.
+func (w *WeatherSystem) Set(z *Zone, kind string) {
+	old := w.In(z)
+	w.lock.Lock()
+	w.current[z] = kind
+	w.lock.Unlock()
+	if old == kind {
+		return
+	}
+	s := WeatherStimulus{from: old, to: kind}
+	for _, r := range(w.universe.Rooms) {
+		if r.zone == z && r.Outdoors() { r.TryBroadcast(s) }
+	}
+}
+
+// WeatherAt is the weather in r, or "" indoors or with no weather.
+func WeatherAt(r *Room) string {
+	if r == nil || r.universe == nil || r.universe.Weather == nil ||
+		!r.Outdoors() {
+		return ""
+	}
+	return r.universe.Weather.In(r.zone)
+}
+
+// GrowthFactor is how fast things grow in r, compared to usual.
+func GrowthFactor(r *Room) float64 {
+	if factor, ok := weatherGrowth[WeatherAt(r)]; ok {
+		return factor
+	}
+	return 1.0
+}
+
+type WeatherStimulus struct {
+	Stimulus
+	from, to string
+}
+
+func (s WeatherStimulus) StimType() string { return "weather" }
+func (s WeatherStimulus) From() string { return s.from }
+func (s WeatherStimulus) To() string { return s.to }
+func (s WeatherStimulus) Description(p Perceiver) string {
+	return weatherChanges[s.to] + "\n"
+}
+
+func doesPerceiveWeather(p Player, s Stimulus) bool { return true }
+
+func describeWeather(r *Room, p *Player) string {
+	return weatherLooks[WeatherAt(r)]
+}
+
+func weatherCommand(p *Player, args []string) {
+	r := p.room
+	if len(args) == 0 {
+		if kind := WeatherAt(r); kind != "" {
+			p.WriteString(weatherLooks[kind] + "\n")
+		} else {
+			p.WriteString("You can't see the sky from here.\n")
+		}
+		return
+	}
+	if p.Universe.Weather == nil {
+		p.WriteString("There is no weather.\n")
+		return
+	}
+	if (r.zone == nil && !p.IsBuilder()) || (r.zone != nil && !r.zone.CanEdit(p)) {
+		p.WriteString("You can only change the weather in zones you own.\n")
+		return
+	}
+	if _, ok := weatherLooks[args[0]]; !ok || len(args) != 1 {
+		p.WriteString("Weather usage: weather [clear|rain|storm|snow].\n")
+		return
+	}
+	EditWeather(p, r.zone, args[0])
+	p.WriteString("The weather is now " + args[0] + ".\n")
+}
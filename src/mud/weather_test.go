@@ -0,0 +1,19 @@
+package mud
+
+import "testing"
+
+func TestChooseWeather(t *testing.T) {
+	counts := make(map[string]int)
+	for roll := 0; roll < 100; roll++ {
+		counts[ChooseWeather("winter", roll)]++
+	}
+	for kind, chance := range(seasonWeather["winter"]) {
+		if counts[kind] != chance {
+			t.Errorf("%s came up %d times in 100, expected %d",
+				kind, counts[kind], chance)
+		}
+	}
+	if ChooseWeather("summer", 99) == Snow {
+		t.Errorf("it should never snow in summer")
+	}
+}
@@ -0,0 +1,112 @@
+package mud
+
+import "fmt"
+
+/*
+ Measured is implemented by PhysicalObjects with a weight (in pounds),
+ a bulk (the room they take up in hands, packs and containers) and a
+ base value. Objects which aren't Measured weigh a pound, have a bulk
+ of one and are worth nothing. For a stack these are each one's, and
+ the functions below count the whole stack.
+ */
+type Measured interface {
+	Weight() int
+	Bulk() int
+	Value() Currency
+}
+
+// WeightOf is o's weight, counting everything inside it
+func WeightOf(o PhysicalObject) int {
+	weight := 1
+	if m, ok := o.(Measured); ok {
+		weight = m.Weight()
+	}
+	weight *= CountOf(o)
+	if c, ok := o.(Container); ok {
+		for _, inside := range(c.Contents()) { weight += WeightOf(inside) }
+	}
+	return weight
+}
+
+func BulkOf(o PhysicalObject) int {
+	if m, ok := o.(Measured); ok {
+		return m.Bulk() * CountOf(o)
+	}
+	return CountOf(o)
+}
+
+func ValueOf(o PhysicalObject) Currency {
+	if m, ok := o.(Measured); ok {
+		return m.Value() * Currency(CountOf(o))
+	}
+	return 0
+}
+
+// MeasuresOf describes o's weight and value for examine
+func MeasuresOf(o PhysicalObject) string {
+	if _, ok := o.(Measured); !ok {
+		return ""
+	}
+	text := fmt.Sprintf("It weighs %d pounds", WeightOf(o))
+	if WeightOf(o) == 1 {
+		text = "It weighs a pound"
+	}
+	if value := ValueOf(o); value > 0 {
+		text += fmt.Sprintf(" and is worth %d bitbux", value)
+	}
+	return text + ".\n"
+}
+
+type Encumbrance int
+
+const (
+	Unencumbered Encumbrance = iota
+	Burdened
+	Overloaded
+)
+
+func (e Encumbrance) String() string {
+	switch e {
+	case Burdened:
+		return "burdened"
+	case Overloaded:
+		return "overloaded"
+	}
+	return "unencumbered"
+}
+
+// Strength is p's strength with what their equipment adds
+func (p *Player) Strength() int { return p.strength + p.Modifier("strength") }
+func (p *Player) SetStrength(strength int) { p.strength = strength }
+
+// MaxLoad is the bulk p can carry: MAX_INVENTORY at strength 10
+func (p *Player) MaxLoad() int { return maxLoad(p.Strength()) }
+
+// MaxWeight is the most p can lift, five pounds per point of strength
+func (p *Player) MaxWeight() int { return maxWeight(p.Strength()) }
+
+func maxLoad(strength int) int { return MAX_INVENTORY * strength / 10 }
+func maxWeight(strength int) int { return 5 * strength }
+
+// CarriedWeight counts what p wears as well as what they carry
+func (p *Player) CarriedWeight() int {
+	weight := 0
+	for _, o := range(p.Inventory()) { weight += WeightOf(o) }
+	for _, o := range(p.Equipped()) { weight += WeightOf(o) }
+	return weight
+}
+
+/*
+ Encumbrance is how p's load slows them down: burdened when carrying
+ more than half what they can lift, and overloaded when carrying more
+ than they can lift (as after losing strength).
+ */
+func (p *Player) Encumbrance() Encumbrance {
+	switch weight := p.CarriedWeight(); {
+	case weight > p.MaxWeight():
+		return Overloaded
+	case weight * 2 > p.MaxWeight():
+		return Burdened
+	}
+	return Unencumbered
+}
@@ -0,0 +1,40 @@
+package mud
+
+import "testing"
+
+type testWeight struct {
+	testObject
+	weight int
+}
+
+func (o *testWeight) Weight() int { return o.weight }
+func (o *testWeight) Bulk() int { return 2 }
+func (o *testWeight) Value() Currency { return 0 }
+
+func TestEncumbrance(t *testing.T) {
+	p := &Player{strength: DefaultStrength,
+		inventory: NewFlexContainer("PhysicalObjects")}
+	bag := &testContainer{capacity: 4}
+	anvil := &testWeight{testObject: testObject{name: "anvil"}, weight: 30}
+	bag.AddContent(anvil)
+
+	if WeightOf(bag) != 31 || Load(bag) != 3 {
+		t.Errorf("bag should weigh 31 with load 3, got %d and %d",
+			WeightOf(bag), Load(bag))
+	}
+	if !p.CanCarry(bag) {
+		t.Fatal("player should be able to carry the bag")
+	}
+	p.Add(bag)
+	if p.Encumbrance() != Burdened {
+		t.Errorf("31 of %d pounds should be burdened, got %s",
+			p.MaxWeight(), p.Encumbrance())
+	}
+	if p.CanCarry(&testWeight{weight: 20}) {
+		t.Error("player shouldn't lift 51 pounds")
+	}
+	p.SetStrength(5)
+	if p.Encumbrance() != Overloaded || p.MaxLoad() != MAX_INVENTORY / 2 {
+		t.Errorf("weaker player should be overloaded, got %s", p.Encumbrance())
+	}
+}
@@ -0,0 +1,450 @@
+package mud
+
+import ("bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings")
+
+// Files LoadWorldDir reads from a world directory
+const WorldDefExtension = ".world"
+
+// DefPos is where something was defined, for error messages
+type DefPos struct {
+	File string
+	Line int
+}
+
+func (d DefPos) String() string { return fmt.Sprintf("%s:%d", d.File, d.Line) }
+
+func (d DefPos) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %s", d, fmt.Sprintf(format, args...))
+}
+
+type ZoneDef struct {
+	Pos DefPos
+	ID string
+	Name string
+	MinLevel, MaxLevel int
+	ResetPolicy string
+	Owners []string
+	Properties map[string]string
+}
+
+// ExitDef is a connection from the room it is listed in
+type ExitDef struct {
+	Pos DefPos
+	Name string
+	To string
+	Return string
+	Kind string
+	State DoorState
+	Key string
+	// Set by the condition, refusal, depart and arrive fields after it
+	Options ExitOptions
+}
+
+// Placement puts a new object, made by a Prototype, in a room
+type Placement struct {
+	Pos DefPos
+	Prototype string
+}
+
+type RoomDef struct {
+	Pos DefPos
+	ID string
+	Zone string
+	Text []string
+	Properties map[string]string
+	Flags []string
+	Extras map[string]string
+	Exits []ExitDef
+	Places []Placement
+	Resets []ResetRule
+	ResetPos []DefPos
+}
+
+/*
+ WorldDef describes a world to seed: zones, rooms with their exits and
+ the objects and NPCs placed in them, each named by an ID local to the
+ definition. The first room defined is where players start.
+ */
+type WorldDef struct {
+	Zones []*ZoneDef
+	Rooms []*RoomDef
+}
+
+var doorStates = map[string]DoorState{
+	"open": DoorOpen, "closed": DoorClosed, "locked": DoorLocked }
+
+func parseZoneField(z *ZoneDef, field string, value string) error {
+	switch field {
+	case "name":
+		z.Name = value
+	case "levels":
+		if _, err := fmt.Sscanf(value, "%d %d", &z.MinLevel, &z.MaxLevel); err != nil {
+			return fmt.Errorf("levels should be [min] [max]")
+		}
+	case "reset":
+		z.ResetPolicy = value
+	case "owner":
+		z.Owners = append(z.Owners, value)
+	case "property":
+		parts := strings.SplitN(value, " ", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("property should be [key] [value]")
+		}
+		z.Properties[parts[0]] = strings.TrimSpace(parts[1])
+	default:
+		return fmt.Errorf("unrecognized zone field '%s'", field)
+	}
+	return nil
+}
+
+/*
+ parseExit reads "[exit], [room][, [return exit][, [kind]]]". The
+ return exit defaults to the opposite of a compass exit. Doors give
+ their state and key after the kind: "door locked brass key".
+ */
+func parseExit(value string) (ExitDef, error) {
+	parts := strings.Split(value, ",")
+	for i := range(parts) { parts[i] = strings.TrimSpace(parts[i]) }
+	if len(parts) < 2 || len(parts) > 4 || parts[0] == "" || parts[1] == "" {
+		return ExitDef{}, fmt.Errorf("exit should be [exit], [room][, [return exit][, [kind]]]")
+	}
+	e := ExitDef{Name: parts[0], To: parts[1], Kind: "simple"}
+	if len(parts) > 2 {
+		e.Return = parts[2]
+	}
+	if len(parts) > 3 && parts[3] != "" {
+		words := strings.SplitN(parts[3], " ", 3)
+		e.Kind = words[0]
+		if e.Kind == "door" {
+			e.State = DoorClosed
+			if len(words) > 1 {
+				state, ok := doorStates[words[1]]
+				if !ok {
+					return ExitDef{}, fmt.Errorf("doors are open, closed or locked")
+				}
+				e.State = state
+			}
+			if len(words) > 2 {
+				e.Key = words[2]
+			}
+		} else if len(words) > 1 {
+			return ExitDef{}, fmt.Errorf("only doors take options")
+		}
+	}
+	if _, known := linkKinds[e.Kind]; !known && e.Kind != "portal" {
+		return ExitDef{}, fmt.Errorf("unknown exit kind '%s'", e.Kind)
+	}
+	if e.Return == "" && e.Kind != "oneway" && e.Kind != "portal" {
+		opposite, ok := OppositeExit(e.Name)
+		if !ok {
+			return ExitDef{}, fmt.Errorf("no return exit known for '%s'", e.Name)
+		}
+		e.Return = opposite
+	}
+	return e, nil
+}
+
+/*
+ setExitOption sets an option on the exit listed before it. Departing
+ and arriving messages are for travel through the exit, or back through
+ its return exit.
+ */
+func setExitOption(o *ExitOptions, field string, value string) error {
+	switch field {
+	case "condition":
+		if _, known := ExitConditions[strings.SplitN(value, " ", 2)[0]]; !known {
+			return fmt.Errorf("unknown exit condition '%s'", value)
+		}
+		o.Condition = value
+	case "refusal":
+		o.Refusal = value
+	case "depart":
+		o.Depart[SideA] = value
+	case "arrive":
+		o.Arrive[SideA] = value
+	case "return depart":
+		o.Depart[SideB] = value
+	case "return arrive":
+		o.Arrive[SideB] = value
+	}
+	return nil
+}
+
+func parseRoomField(r *RoomDef, pos DefPos, field string, value string) error {
+	switch field {
+	case "zone":
+		r.Zone = value
+	case "text":
+		r.Text = append(r.Text, value)
+	case "property":
+		parts := strings.SplitN(value, " ", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("property should be [key] [value]")
+		}
+		r.Properties[parts[0]] = strings.TrimSpace(parts[1])
+	case "flag":
+		if _, known := roomFlagHelp[value]; !known {
+			return fmt.Errorf("unknown room flag '%s'", value)
+		}
+		r.Flags = append(r.Flags, value)
+	case "extra":
+		parts := strings.SplitN(value, " ", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("extra should be [keyword] [text]")
+		}
+		r.Extras[strings.ToLower(parts[0])] = strings.TrimSpace(parts[1])
+	case "exit":
+		e, err := parseExit(value)
+		if err != nil {
+			return err
+		}
+		e.Pos = pos
+		r.Exits = append(r.Exits, e)
+	case "condition", "refusal", "depart", "arrive", "return depart", "return arrive":
+		if len(r.Exits) == 0 {
+			return fmt.Errorf("%s should follow an exit", field)
+		}
+		return setExitOption(&r.Exits[len(r.Exits)-1].Options, field, value)
+	case "place":
+		r.Places = append(r.Places, Placement{Pos: pos, Prototype: value})
+	case "reset":
+		rule, err := ParseResetRule(value)
+		if err != nil {
+			return err
+		}
+		r.Resets = append(r.Resets, rule)
+		r.ResetPos = append(r.ResetPos, pos)
+	default:
+		return fmt.Errorf("unrecognized room field '%s'", field)
+	}
+	return nil
+}
+
+/*
+ ParseWorldDef adds the zones and rooms in a world file to def. A file
+ has sections headed [zone id] or [room id], followed by "field: value"
+ lines; # starts a comment. Errors give the file and line.
+ */
+func ParseWorldDef(file string, r io.Reader, def *WorldDef) error {
+	var zone *ZoneDef
+	var room *RoomDef
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		pos := DefPos{file, lineNo}
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			header := strings.Fields(line[1:len(line)-1])
+			if len(header) != 2 {
+				return pos.errorf("sections are [zone id] or [room id]")
+			}
+			zone, room = nil, nil
+			switch header[0] {
+			case "zone":
+				zone = &ZoneDef{Pos: pos, ID: header[1], Name: header[1],
+					Properties: make(map[string]string)}
+				def.Zones = append(def.Zones, zone)
+			case "room":
+				room = &RoomDef{Pos: pos, ID: header[1],
+					Properties: make(map[string]string),
+					Extras: make(map[string]string)}
+				def.Rooms = append(def.Rooms, room)
+			default:
+				return pos.errorf("unknown section '%s'", header[0])
+			}
+		default:
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) != 2 {
+				return pos.errorf("expected [field]: [value]")
+			}
+			field, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			var err error
+			switch {
+			case zone != nil:
+				err = parseZoneField(zone, field, value)
+			case room != nil:
+				err = parseRoomField(room, pos, field, value)
+			default:
+				err = fmt.Errorf("field outside of a section")
+			}
+			if err != nil {
+				return pos.errorf("%s", err)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+/*
+ LoadWorldDir parses every .world file in dir, in name order, into one
+ WorldDef.
+ */
+func LoadWorldDir(dir string) (*WorldDef, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*" + WorldDefExtension))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s files in %s", WorldDefExtension, dir)
+	}
+	def := new(WorldDef)
+	for _, file := range(files) {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		err = ParseWorldDef(file, f, def)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return def, nil
+}
+
+/*
+ Validate checks that everything def refers to is defined: zones,
+ rooms, prototypes and reset policies, and that no room has the same
+ exit twice (counting return exits).
+ */
+func (def *WorldDef) Validate() error {
+	zones := make(map[string]*ZoneDef)
+	for _, z := range(def.Zones) {
+		if other, dup := zones[z.ID]; dup {
+			return z.Pos.errorf("zone %s is already defined at %s", z.ID, other.Pos)
+		}
+		zones[z.ID] = z
+		switch z.ResetPolicy {
+		case "", ResetAlways, ResetWhenEmpty, ResetNever:
+		default:
+			return z.Pos.errorf("unknown reset policy '%s'", z.ResetPolicy)
+		}
+	}
+	if len(def.Rooms) == 0 {
+		return fmt.Errorf("the world has no rooms")
+	}
+	rooms := make(map[string]*RoomDef)
+	for _, r := range(def.Rooms) {
+		if other, dup := rooms[r.ID]; dup {
+			return r.Pos.errorf("room %s is already defined at %s", r.ID, other.Pos)
+		}
+		rooms[r.ID] = r
+		if _, ok := zones[r.Zone]; r.Zone != "" && !ok {
+			return r.Pos.errorf("room %s is in unknown zone %s", r.ID, r.Zone)
+		}
+		for _, place := range(r.Places) {
+			if _, ok := Prototypes[place.Prototype]; !ok {
+				return place.Pos.errorf("unknown prototype '%s'", place.Prototype)
+			}
+		}
+		for i, rule := range(r.Resets) {
+			if _, ok := Prototypes[rule.Prototype]; !ok {
+				return r.ResetPos[i].errorf("unknown prototype '%s'", rule.Prototype)
+			}
+		}
+	}
+
+	exits := make(map[string]DefPos)
+	addExit := func(room string, name string, pos DefPos) error {
+		key := room + "|" + name
+		if other, dup := exits[key]; dup {
+			return pos.errorf("room %s already has exit %s (%s)", room, name, other)
+		}
+		exits[key] = pos
+		return nil
+	}
+	for _, r := range(def.Rooms) {
+		for _, e := range(r.Exits) {
+			if _, ok := rooms[e.To]; !ok {
+				return e.Pos.errorf("exit %s leads to unknown room %s", e.Name, e.To)
+			}
+			name, returnName := e.exitNames()
+			if err := addExit(r.ID, name, e.Pos); err != nil {
+				return err
+			}
+			if e.Kind != "oneway" {
+				if err := addExit(e.To, returnName, e.Pos); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// exitNames are the names of the exits each way, as players see them
+func (e ExitDef) exitNames() (string, string) {
+	if e.Kind == "portal" {
+		return "enter " + e.Name, "leave " + e.Name
+	}
+	return e.Name, e.Return
+}
+
+func (e ExitDef) creator() RoomConnCreator {
+	var makeConn RoomConnCreator
+	switch e.Kind {
+	case "door":
+		makeConn = DoorRoomConnectCreator(e.Name, e.Return, e.Key, e.State)
+	case "portal":
+		makeConn = PortalRoomConnectCreator(e.Name)
+	default:
+		makeConn = linkKinds[e.Kind](e.Name, e.Return)
+	}
+	return func() RoomConnection {
+		rc := makeConn()
+		// keep the options the kind sets, such as OneWay and Hidden
+		o := rc.Options()
+		o.Condition, o.Refusal = e.Options.Condition, e.Options.Refusal
+		o.Depart, o.Arrive = e.Options.Depart, e.Options.Arrive
+		return rc
+	}
+}
+
+/*
+ SeedWorld validates def and builds it in u, returning the room where
+ players start. Nothing is built if def isn't valid.
+ */
+func SeedWorld(u *Universe, def *WorldDef) (*Room, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	zones := make(map[string]*Zone)
+	for _, zd := range(def.Zones) {
+		z := NewZone(u, zd.Name)
+		z.SetLevelRange(zd.MinLevel, zd.MaxLevel)
+		if zd.ResetPolicy != "" { z.SetResetPolicy(zd.ResetPolicy) }
+		for _, owner := range(zd.Owners) { z.AddOwner(owner) }
+		for k, v := range(zd.Properties) { z.SetProperty(k, v) }
+		zones[zd.ID] = z
+	}
+
+	rooms := make(map[string]*Room)
+	for _, rd := range(def.Rooms) {
+		r := NewRoom(u, 0, strings.Join(rd.Text, "\n"))
+		r.zone = zones[rd.Zone]
+		for k, v := range(rd.Properties) { r.SetProperty(k, v) }
+		for _, flag := range(rd.Flags) { r.SetFlag(flag, true) }
+		for k, v := range(rd.Extras) { r.SetExtraDescription(k, v) }
+		r.resets = rd.Resets
+		for _, place := range(rd.Places) {
+			o, _ := MakePrototype(u, place.Prototype)
+			o.SetRoom(r)
+			r.AddChild(o)
+		}
+		rooms[rd.ID] = r
+	}
+	for _, rd := range(def.Rooms) {
+		for _, e := range(rd.Exits) {
+			ConnectWithConnCreator(e.creator())(rooms[rd.ID], rooms[e.To])
+		}
+	}
+	Log("Seeded", len(def.Rooms), "rooms in", len(def.Zones), "zones")
+	return rooms[def.Rooms[0].ID], nil
+}
@@ -0,0 +1,133 @@
+package mud
+
+import ("strings"
+	"testing")
+
+const testWorld = `
+# a comment
+[zone town]
+name: Town
+levels: 1 10
+property: outdoors yes
+
+[room square]
+zone: town
+text: Town Square.
+text:
+text: The middle of town.
+flag: safe
+extra: fountain A dry fountain.
+exit: east, lane
+condition: money 10
+refusal: A guard stops you.
+exit: portal, cellar, , portal
+
+[room lane]
+zone: town
+text: A lane.
+exit: down, cellar, up, door locked brass key
+
+[room cellar]
+text: A cellar.
+exit: chute, square, , oneway
+exit: north, lane, , hidden
+depart: climbs the steps
+return arrive: comes down the steps
+`
+
+func parseTestWorld(t *testing.T, text string) *WorldDef {
+	def := new(WorldDef)
+	if err := ParseWorldDef("test.world", strings.NewReader(text), def); err != nil {
+		t.Fatalf("ParseWorldDef returned error: %s", err)
+	}
+	return def
+}
+
+func TestParseWorldDef(t *testing.T) {
+	def := parseTestWorld(t, testWorld)
+	if len(def.Zones) != 1 || def.Zones[0].Name != "Town" || def.Zones[0].MaxLevel != 10 {
+		t.Errorf("unexpected zones %+v", def.Zones)
+	}
+	if len(def.Rooms) != 3 {
+		t.Fatalf("expected 3 rooms, got %d", len(def.Rooms))
+	}
+	square := def.Rooms[0]
+	if text := strings.Join(square.Text, "\n"); text != "Town Square.\n\nThe middle of town." {
+		t.Errorf("unexpected text %q", text)
+	}
+	if square.Exits[0].Return != "west" {
+		t.Errorf("return exit should default to west, got %q", square.Exits[0].Return)
+	}
+	if o := square.Exits[0].Options; o.Condition != "money 10" || o.Refusal != "A guard stops you." {
+		t.Errorf("unexpected exit options %+v", o)
+	}
+	door := def.Rooms[1].Exits[0]
+	if door.Kind != "door" || door.State != DoorLocked || door.Key != "brass key" {
+		t.Errorf("unexpected door %+v", door)
+	}
+	if err := def.Validate(); err != nil {
+		t.Errorf("Validate returned error: %s", err)
+	}
+}
+
+func TestWorldDefErrors(t *testing.T) {
+	for text, want := range(map[string]string{
+		"[room a]\ncolour: red\n": "test.world:2: unrecognized room field",
+		"[room a]\nflag: sparkly\n": "test.world:2: unknown room flag",
+		"text: stray\n": "test.world:1: field outside of a section",
+		"[room a]\nexit: sideways, b\n": "test.world:2: no return exit known",
+		"[room a]\ncondition: money 5\n": "test.world:2: condition should follow an exit",
+		"[room a]\nexit: east, b\ncondition: luck 5\n": "test.world:3: unknown exit condition",
+	}) {
+		err := ParseWorldDef("test.world", strings.NewReader(text), new(WorldDef))
+		if err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+
+	for text, want := range(map[string]string{
+		"[room a]\nexit: east, b\n": "test.world:2: exit east leads to unknown room b",
+		"[room a]\nzone: z\n": "test.world:1: room a is in unknown zone z",
+		"[room a]\nplace: unicorn\n": "test.world:2: unknown prototype 'unicorn'",
+		"[room a]\nexit: east, b\n[room b]\nexit: west, a\n":
+			"test.world:4: room b already has exit west (test.world:2)",
+	}) {
+		err := parseTestWorld(t, text).Validate()
+		if err == nil || err.Error() != want {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+}
+
+func TestSeedWorldExitOptions(t *testing.T) {
+	u := testUniverse()
+	square, err := SeedWorld(u, parseTestWorld(t, testWorld))
+	if err != nil {
+		t.Fatalf("SeedWorld returned error: %s", err)
+	}
+	east := square.exits[0].exit.Options()
+	if east.Condition != "money 10" || east.Refusal != "A guard stops you." {
+		t.Errorf("unexpected east options %+v", east)
+	}
+	var cellar *Room
+	for _, exit := range(square.exits) {
+		if exit.Name() == "enter portal" { cellar = exit.OtherSide() }
+	}
+	if cellar == nil {
+		t.Fatalf("square has no portal")
+	}
+	for _, exit := range(cellar.exits) {
+		o := exit.exit.Options()
+		switch exit.Name() {
+		case "chute":
+			if !o.OneWay {
+				t.Errorf("chute should be one way")
+			}
+		case "north":
+			if !o.Hidden || o.Depart[SideA] != "climbs the steps" ||
+				o.Arrive[SideB] != "comes down the steps" {
+				t.Errorf("unexpected north options %+v", *o)
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package mud
+
+import ("encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings")
+
+// Version of the world file format written by ExportWorld
+const WorldFileFormat = 1
+
+/*
+ WorldFile is the world as saved in Redis, in a form for JSON: zones,
+ rooms, room connections and the persisted objects in rooms, each as
+ the values its PersistentKeys name. Records are ordered by ID, so
+ exports of the same world are identical.
+ */
+type WorldFile struct {
+	Format int `json:"format"`
+	Zones []Pvals `json:"zones"`
+	Rooms []Pvals `json:"rooms"`
+	RoomConnects []Pvals `json:"roomConnects"`
+	Objects []WorldObject `json:"objects"`
+}
+
+// WorldObject is a persisted object of any type with PersistentKeys
+type WorldObject struct {
+	Type string `json:"type"`
+	Values Pvals `json:"values"`
+}
+
+func sortedIDs(ids []string) []string {
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.Atoi(ids[i])
+		b, _ := strconv.Atoi(ids[j])
+		return a < b
+	})
+	return ids
+}
+
+// loadRecord loads a structure's values, with the extra keys of its
+// kind if it has one (as room connections do).
+func loadRecord(store *TinyDB, dbType string, id string) Pvals {
+	fullDbUrl := FieldJoin(":", dbType, id)
+	vals := store.LoadStructure(PersistentKeys[dbType], fullDbUrl)
+	if kind, ok := vals["kind"].(string); ok {
+		if extraKeys, ok := PersistentKeys[FieldJoin(":", dbType, kind)]; ok {
+			for k, v := range(store.LoadStructure(extraKeys, fullDbUrl)) {
+				vals[k] = v
+			}
+		}
+	}
+	for k, v := range(vals) {
+		if set, isSet := v.([]string); isSet {
+			sort.Strings(set)
+			vals[k] = set
+		}
+	}
+	return vals
+}
+
+func loadRecords(store *TinyDB, dbType string, globalSet string) []Pvals {
+	records := []Pvals{}
+	for _, id := range(sortedIDs(store.GlobalSetGet(globalSet))) {
+		records = append(records, loadRecord(store, dbType, id))
+	}
+	return records
+}
+
+/*
+ exportObjects appends the named objects to objects, followed by
+ what they hold (the objects named by their "contents").
+ */
+func exportObjects(store *TinyDB, names []string, objects []WorldObject) []WorldObject {
+	for _, name := range(names) {
+		parts := strings.SplitN(name, ":", 2)
+		if _, known := PersistentKeys[parts[0]]; !known || len(parts) != 2 {
+			Log("[WARN] can't export", name)
+			continue
+		}
+		vals := loadRecord(store, parts[0], parts[1])
+		objects = append(objects, WorldObject{Type: parts[0], Values: vals})
+		contents, _ := vals["contents"].([]string)
+		objects = exportObjects(store, contents, objects)
+	}
+	return objects
+}
+
+// ExportWorld writes the world saved in u's store to w as JSON.
+func ExportWorld(u *Universe, w io.Writer) error {
+	world := WorldFile{Format: WorldFileFormat}
+	world.Zones = loadRecords(u.Store, "zone", "zones")
+	world.Rooms = loadRecords(u.Store, "room", "rooms")
+	world.RoomConnects = loadRecords(u.Store, "roomConnect", "roomConnects")
+
+	for _, room := range(world.Rooms) {
+		persisters, _ := room["persisters"].([]string)
+		world.Objects = exportObjects(u.Store, persisters, world.Objects)
+	}
+
+	out, err := json.MarshalIndent(world, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
+
+// storeValues puts JSON values back in the forms RedisSet takes
+func storeValues(vals Pvals) (Pvals, error) {
+	stored := make(Pvals)
+	for k, v := range(vals) {
+		switch ty := v.(type) {
+		case string:
+			stored[k] = ty
+		case []interface{}:
+			set := []string{}
+			for _, member := range(ty) {
+				s, ok := member.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s should hold strings", k)
+				}
+				set = append(set, s)
+			}
+			stored[k] = set
+		default:
+			return nil, fmt.Errorf("%s should be a string or list", k)
+		}
+	}
+	return stored, nil
+}
+
+// worldRecord is a record of a world file, ready to save
+type worldRecord struct {
+	dbType string
+	globalSet string
+	vals Pvals
+}
+
+// worldRecords checks every record of world, putting them in the forms the store takes
+func worldRecords(world *WorldFile) ([]worldRecord, error) {
+	records := []worldRecord{}
+	add := func(dbType string, globalSet string, raw Pvals) error {
+		vals, err := storeValues(raw)
+		if err != nil {
+			return fmt.Errorf("%s %v: %s", dbType, raw["id"], err)
+		}
+		if _, err := strconv.Atoi(stringVal(vals, "id")); err != nil {
+			return fmt.Errorf("%s without a numeric id", dbType)
+		}
+		records = append(records, worldRecord{dbType, globalSet, vals})
+		return nil
+	}
+	for _, z := range(world.Zones) {
+		if err := add("zone", "zones", z); err != nil { return nil, err }
+	}
+	for _, room := range(world.Rooms) {
+		if err := add("room", "rooms", room); err != nil { return nil, err }
+	}
+	for _, rc := range(world.RoomConnects) {
+		if err := add("roomConnect", "roomConnects", rc); err != nil { return nil, err }
+	}
+	for _, o := range(world.Objects) {
+		if _, known := PersistentKeys[o.Type]; !known {
+			return nil, fmt.Errorf("unknown object type %s", o.Type)
+		}
+		if err := add(o.Type, "", o.Values); err != nil { return nil, err }
+	}
+	return records, nil
+}
+
+/*
+ clearWorld deletes the zones, rooms and room connections saved in
+ store, and the objects in the rooms, leaving everything else (such
+ as players) as it is.
+ */
+func clearWorld(store *TinyDB) {
+	deleteRecord := func(dbType string, vals Pvals) {
+		keys := []string{}
+		for key := range(vals) { keys = append(keys, key) }
+		store.DeleteStructure(dbType, stringVal(vals, "id"), keys)
+	}
+	objects := []WorldObject{}
+	for _, room := range(loadRecords(store, "room", "rooms")) {
+		persisters, _ := room["persisters"].([]string)
+		objects = exportObjects(store, persisters, objects)
+	}
+	for _, o := range(objects) {
+		deleteRecord(o.Type, o.Values)
+	}
+	for _, kind := range([][2]string{{"zone", "zones"}, {"room", "rooms"},
+			{"roomConnect", "roomConnects"}}) {
+		for _, vals := range(loadRecords(store, kind[0], kind[1])) {
+			deleteRecord(kind[0], vals)
+			store.RemoveFromGlobalSet(kind[1], stringVal(vals, "id"))
+		}
+	}
+}
+
+/*
+ ImportWorld replaces the world saved in u's store with the world in a
+ file written by ExportWorld, keeping its IDs. The whole file is read
+ and checked first, so a bad file changes nothing. Players and other
+ records which aren't part of the world are kept. The world is loaded
+ from the store as usual afterwards.
+ */
+func ImportWorld(u *Universe, r io.Reader) error {
+	var world WorldFile
+	if err := json.NewDecoder(r).Decode(&world); err != nil {
+		return err
+	}
+	if world.Format != WorldFileFormat {
+		return fmt.Errorf("unknown world file format %d", world.Format)
+	}
+	records, err := worldRecords(&world)
+	if err != nil {
+		return err
+	}
+
+	clearWorld(u.Store)
+	for _, record := range(records) {
+		id := stringVal(record.vals, "id")
+		u.Store.SaveStructure(record.dbType, record.vals)
+		n, _ := strconv.Atoi(id)
+		u.Store.RaiseIDCounter(record.dbType, n)
+		if record.globalSet != "" {
+			u.Store.AddToGlobalSet(record.globalSet, id)
+		}
+	}
+	return nil
+}
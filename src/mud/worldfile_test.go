@@ -0,0 +1,107 @@
+package mud
+
+import ("bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing")
+
+func TestStoreValues(t *testing.T) {
+	var raw Pvals
+	json.Unmarshal([]byte(`{"id": "3", "flags": ["safe", "recall"]}`), &raw)
+	vals, err := storeValues(raw)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	expected := Pvals{"id": "3", "flags": []string{"safe", "recall"}}
+	if !reflect.DeepEqual(vals, expected) {
+		t.Errorf("got %v, expected %v", vals, expected)
+	}
+
+	json.Unmarshal([]byte(`{"id": 3}`), &raw)
+	if _, err := storeValues(raw); err == nil {
+		t.Errorf("numbers should be rejected")
+	}
+}
+
+func TestExportImportWorld(t *testing.T) {
+	PersistentKeys["testBag"] = []string{ "id", "name", "contents" }
+	PersistentKeys["testPebble"] = []string{ "id", "name" }
+	u := testUniverse()
+	save := func(dbType string, vals Pvals) {
+		u.Store.SaveStructure(dbType, vals)
+		u.Store.RaiseIDCounter(dbType, 9)
+	}
+	save("zone", Pvals{"id": "1", "name": "Cellars"})
+	u.Store.AddToGlobalSet("zones", "1")
+	save("room", Pvals{"id": "2", "text": "A cellar.", "zone": "1",
+		"persisters": []string{"testBag:3"}})
+	save("room", Pvals{"id": "4", "text": "A vault.", "persisters": []string{}})
+	u.Store.AddToGlobalSet("rooms", "2")
+	u.Store.AddToGlobalSet("rooms", "4")
+	save("roomConnect", Pvals{"id": "5", "kind": "simple", "roomAId": "2",
+		"roomBId": "4", "aExitName": "down", "bExitName": "up"})
+	u.Store.AddToGlobalSet("roomConnects", "5")
+	save("testBag", Pvals{"id": "3", "name": "sack", "contents": []string{"testBag:6"}})
+	save("testBag", Pvals{"id": "6", "name": "pouch", "contents": []string{"testPebble:7"}})
+	save("testPebble", Pvals{"id": "7", "name": "pebble"})
+
+	var exported bytes.Buffer
+	if err := ExportWorld(u, &exported); err != nil {
+		t.Fatalf("couldn't export: %s", err)
+	}
+	imported := testUniverse()
+	if err := ImportWorld(imported, bytes.NewReader(exported.Bytes())); err != nil {
+		t.Fatalf("couldn't import: %s", err)
+	}
+	var reexported bytes.Buffer
+	ExportWorld(imported, &reexported)
+	if exported.String() != reexported.String() {
+		t.Errorf("the world changed on import:\n%s\nbecame\n%s",
+			exported.String(), reexported.String())
+	}
+
+	pebble := imported.Store.LoadStructure(PersistentKeys["testPebble"], "testPebble:7")
+	pouch := imported.Store.LoadStructure(PersistentKeys["testBag"], "testBag:6")
+	if stringVal(pebble, "name") != "pebble" ||
+		!reflect.DeepEqual(pouch["contents"], []string{"testPebble:7"}) {
+		t.Errorf("nested contents not imported: %v, %v", pouch, pebble)
+	}
+	if id := imported.Store.SaveStructure("testPebble", Pvals{"name": "grit"}); id != "8" {
+		t.Errorf("new objects should be numbered after the imported, got %s", id)
+	}
+}
+
+func TestImportBadWorldKeepsStore(t *testing.T) {
+	u := testUniverse()
+	u.Store.SaveStructure("room", Pvals{"id": "2", "text": "A cellar."})
+	u.Store.AddToGlobalSet("rooms", "2")
+	u.Store.SaveStructure("player", Pvals{"id": "1", "name": "Alice"})
+	for _, bad := range([]string{
+		`{"format": 1, "rooms": [{"id": "3"`,
+		`{"format": 1, "rooms": [{"id": "3", "text": "A vault."}],
+			"objects": [{"type": "unheardOf", "values": {"id": "4"}}]}`,
+		`{"format": 1, "rooms": [{"id": "three"}]}`,
+	}) {
+		if ImportWorld(u, strings.NewReader(bad)) == nil {
+			t.Errorf("importing %s should fail", bad)
+		}
+	}
+	room := u.Store.LoadStructure([]string{"id", "text"}, "room:2")
+	if stringVal(room, "text") != "A cellar." || len(u.Store.GlobalSetGet("rooms")) != 1 {
+		t.Errorf("a bad file shouldn't change the world, room is %v", room)
+	}
+
+	good := `{"format": 1, "rooms": [{"id": "3", "text": "A vault."}]}`
+	if err := ImportWorld(u, strings.NewReader(good)); err != nil {
+		t.Fatalf("couldn't import: %s", err)
+	}
+	player := u.Store.LoadStructure([]string{"name"}, "player:1")
+	if rooms := u.Store.GlobalSetGet("rooms"); len(rooms) != 1 || rooms[0] != "3" ||
+		stringVal(player, "name") != "Alice" {
+		t.Errorf("import should replace the rooms, %v, and keep players, %v", rooms, player)
+	}
+	if ok, _ := u.Store.KeyExists("room:2:text"); ok {
+		t.Error("the old room's record should be deleted")
+	}
+}
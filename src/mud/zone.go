@@ -0,0 +1,350 @@
+package mud
+
+import ("fmt"
+	"sort"
+	"strconv"
+	"strings")
+
+func init() {
+	PersistentKeys["zone"] = []string{ "id", "name", "owners",
+		"minLevel", "maxLevel", "resetPolicy", "properties" }
+
+	GlobalCommands["zones"] = listZones
+	GlobalCommands["zone"] = zoneCommand
+	GlobalCommands["zecho"] = zoneEcho
+
+	PlayerPerceptions["zoneAnnounce"] = doesPerceiveZoneAnnounce
+}
+
+// Reset policies, deciding when a Zone's contents may be reset
+const (
+	ResetAlways = "always"
+	ResetWhenEmpty = "empty"
+	ResetNever = "never"
+)
+
+/*
+ Zone groups rooms into an area with a name, owning builders, a
+ suggested level range and a reset policy. Properties set on a zone
+ are the defaults for its rooms (see Room.Property).
+ */
+type Zone struct {
+	Persister
+	id int
+	name string
+	owners map[string]bool
+	minLevel, maxLevel int
+	resetPolicy string
+	properties map[string]string
+	universe *Universe
+}
+
+func newZone(u *Universe, id int, name string) *Zone {
+	z := new(Zone)
+	z.id = id
+	z.name = name
+	z.owners = make(map[string]bool)
+	z.resetPolicy = ResetWhenEmpty
+	z.properties = make(map[string]string)
+	z.universe = u
+	return z
+}
+
+// NewZone creates and saves a zone, so that it has an ID immediately.
+func NewZone(u *Universe, name string) *Zone {
+	z := newZone(u, 0, name)
+	z.Save()
+	u.Zones[z.id] = z
+	u.Add(z)
+	return z
+}
+
+func LoadZone(u *Universe, id int) *Zone {
+	vals := u.Store.LoadStructure(PersistentKeys["zone"],
+		FieldJoin(":","zone",strconv.Itoa(id)))
+	z := newZone(u, id, stringVal(vals, "name"))
+	if owners, ok := vals["owners"].([]string); ok {
+		for _, owner := range(owners) { z.owners[owner] = true }
+	}
+	z.minLevel, _ = strconv.Atoi(stringVal(vals, "minLevel"))
+	z.maxLevel, _ = strconv.Atoi(stringVal(vals, "maxLevel"))
+	if policy := stringVal(vals, "resetPolicy"); policy != "" {
+		z.resetPolicy = policy
+	}
+	if props, ok := vals["properties"].([]string); ok {
+		z.properties = parsePropertyStrings(props)
+	}
+	u.Zones[id] = z
+	u.Add(z)
+	return z
+}
+
+// LoadZones loads every saved zone. Zones must be loaded before rooms.
+func LoadZones(u *Universe) {
+	for _, zoneId := range(u.Store.GlobalSetGet("zones")) {
+		if idNo, err := strconv.Atoi(zoneId); err == nil {
+			LoadZone(u, idNo)
+		} else {
+			Log("[warn] strange zoneId", zoneId)
+		}
+	}
+}
+
+func (z *Zone) ID() int { return z.id }
+func (z *Zone) Name() string { return z.name }
+func (z *Zone) SetName(name string) { z.name = name }
+func (z *Zone) ResetPolicy() string { return z.resetPolicy }
+func (z *Zone) SetResetPolicy(policy string) { z.resetPolicy = policy }
+func (z *Zone) LevelRange() (int, int) { return z.minLevel, z.maxLevel }
+func (z *Zone) SetLevelRange(min int, max int) {
+	z.minLevel, z.maxLevel = min, max
+}
+
+func (z *Zone) Property(key string) (string, bool) {
+	value, ok := z.properties[key]
+	return value, ok
+}
+
+// SetProperty sets a default for the zone's rooms; "" removes it.
+func (z *Zone) SetProperty(key string, value string) {
+	if value == "" {
+		delete(z.properties, key)
+	} else {
+		z.properties[key] = value
+	}
+}
+
+func (z *Zone) Owners() []string {
+	owners := []string{}
+	for owner := range(z.owners) { owners = append(owners, owner) }
+	sort.Strings(owners)
+	return owners
+}
+func (z *Zone) AddOwner(name string) { z.owners[name] = true }
+func (z *Zone) RemoveOwner(name string) { delete(z.owners, name) }
+
+// CanEdit is true for builders who own the zone, or any builder if
+// the zone has no owners.
+func (z *Zone) CanEdit(p *Player) bool {
+	return p.IsBuilder() && (len(z.owners) == 0 || z.owners[p.name])
+}
+
+// Rooms returns the zone's rooms, ordered by ID
+func (z *Zone) Rooms() []*Room {
+	rooms := []*Room{}
+	for _, r := range(z.universe.Rooms) {
+		if r.zone == z { rooms = append(rooms, r) }
+	}
+	sort.Sort(roomsByID(rooms))
+	return rooms
+}
+
+// Broadcast sends s to every room in the zone
+func (z *Zone) Broadcast(s Stimulus) {
+	for _, r := range(z.Rooms()) { r.Broadcast(s) }
+}
+
+type roomsByID []*Room
+
+func (r roomsByID) Len() int { return len(r) }
+func (r roomsByID) Less(i, j int) bool { return r[i].id < r[j].id }
+func (r roomsByID) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+
+func (z *Zone) PersistentValues() map[string]interface{} {
+	vals := make(map[string]interface{})
+	if(z.id > 0) {
+		vals["id"] = strconv.Itoa(z.id)
+	}
+	vals["name"] = z.name
+	vals["owners"] = z.Owners()
+	vals["minLevel"] = strconv.Itoa(z.minLevel)
+	vals["maxLevel"] = strconv.Itoa(z.maxLevel)
+	vals["resetPolicy"] = z.resetPolicy
+	vals["properties"] = propertyStrings(z.properties)
+	return vals
+}
+
+func (z *Zone) Save() string {
+	outID := z.universe.Store.SaveStructure("zone", z.PersistentValues())
+	if(z.id == 0) {
+		z.id, _ = strconv.Atoi(outID)
+		z.universe.Store.AddToGlobalSet("zones", outID)
+	}
+	return outID
+}
+
+func (z *Zone) DBFullName() string {
+	return fmt.Sprintf("zone:%d", z.id)
+}
+
+func (z *Zone) Describe() string {
+	text := fmt.Sprintf("Zone %d: %s\n", z.id, z.name)
+	text += fmt.Sprintf("Levels: %d-%d\n", z.minLevel, z.maxLevel)
+	text += "Owners: " + strings.Join(z.Owners(), ", ") + "\n"
+	text += "Reset policy: " + z.resetPolicy + "\n"
+	text += fmt.Sprintf("Rooms: %d\n", len(z.Rooms()))
+	for _, prop := range(propertyStrings(z.properties)) {
+		text += "Default " + prop + "\n"
+	}
+	return text
+}
+
+type ZoneAnnounceStimulus struct {
+	Stimulus
+	zone *Zone
+	text string
+}
+
+func (s ZoneAnnounceStimulus) StimType() string { return "zoneAnnounce" }
+func (s ZoneAnnounceStimulus) Description(p Perceiver) string {
+	return "[" + s.zone.name + "] " + s.text + "\n"
+}
+func (s ZoneAnnounceStimulus) Zone() *Zone { return s.zone }
+func (s ZoneAnnounceStimulus) Text() string { return s.text }
+
+func doesPerceiveZoneAnnounce(p Player, s Stimulus) bool { return true }
+
+func ZoneAnnounce(z *Zone, text string) ZoneAnnounceStimulus {
+	return ZoneAnnounceStimulus{zone: z, text: text}
+}
+
+func listZones(p *Player, args []string) {
+	ids := []int{}
+	for id := range(p.Universe.Zones) { ids = append(ids, id) }
+	if len(ids) == 0 {
+		p.WriteString("There are no zones.\n")
+		return
+	}
+	sort.Ints(ids)
+	p.WriteString(Divider())
+	for _, id := range(ids) {
+		z := p.Universe.Zones[id]
+		p.WriteString(fmt.Sprintf("[%d] %s (levels %d-%d) owned by %s\n",
+			z.id, z.name, z.minLevel, z.maxLevel,
+			strings.Join(z.Owners(), ", ")))
+	}
+	p.WriteString(Divider())
+}
+
+const zoneUsage = `Zone usage:
+  zone                         show the zone you are in
+  zone create [name]           create a zone containing this room
+  zone add [zone id]           add this room to a zone
+  zone remove                  remove this room from its zone
+  zone name [name]             rename this zone
+  zone levels [min] [max]      set this zone's level range
+  zone reset [always|empty|never]
+  zone owner [add|remove] [player]
+  zone prop [key] [value]      set a default room property
+`
+
+func zoneCommand(p *Player, args []string) {
+	room := p.room
+	if len(args) == 0 {
+		if room.zone == nil {
+			p.WriteString("This room isn't part of any zone.\n")
+		} else {
+			p.WriteString(room.zone.Describe())
+		}
+		return
+	}
+
+	switch args[0] {
+	case "create":
+		if !p.IsBuilder() {
+			p.WriteString("Only builders may create zones.\n")
+			return
+		}
+		if room.zone != nil && !room.zone.CanEdit(p) {
+			p.WriteString("This room belongs to a zone you don't own.\n")
+			return
+		}
+		if len(args) < 2 {
+			p.WriteString(zoneUsage)
+			return
+		}
+		z := NewZone(p.Universe, strings.Join(args[1:], " "))
+		z.AddOwner(p.name)
+		RecordChange(p, z.DBFullName(), "created", "", z.name)
+		EditRoomZone(p, room, z)
+		p.WriteString(fmt.Sprintf("Created zone %d, %s.\n", z.id, z.name))
+		return
+	case "add":
+		if len(args) != 2 {
+			p.WriteString(zoneUsage)
+			return
+		}
+		id, _ := strconv.Atoi(args[1])
+		z, ok := p.Universe.Zones[id]
+		if !ok {
+			p.WriteString("No zone " + args[1] + ".\n")
+			return
+		}
+		if !z.CanEdit(p) || (room.zone != nil && !room.zone.CanEdit(p)) {
+			p.WriteString("You don't own that zone.\n")
+			return
+		}
+		EditRoomZone(p, room, z)
+		p.WriteString("This room is now part of " + z.name + ".\n")
+		return
+	}
+
+	z := room.zone
+	if z == nil {
+		p.WriteString("This room isn't part of any zone.\n")
+		return
+	}
+	if !z.CanEdit(p) {
+		p.WriteString("You don't own this zone.\n")
+		return
+	}
+	switch {
+	case args[0] == "remove":
+		EditRoomZone(p, room, nil)
+		p.WriteString("This room is no longer part of " + z.name + ".\n")
+	case args[0] == "name" && len(args) > 1:
+		EditZoneName(p, z, strings.Join(args[1:], " "))
+		p.WriteString("Zone renamed to " + z.name + ".\n")
+	case args[0] == "levels" && len(args) == 3:
+		min, minErr := strconv.Atoi(args[1])
+		max, maxErr := strconv.Atoi(args[2])
+		if minErr != nil || maxErr != nil || min > max {
+			p.WriteString("Levels must be numbers, min first.\n")
+			return
+		}
+		EditZoneLevels(p, z, min, max)
+		p.WriteString("Level range set.\n")
+	case args[0] == "reset" && len(args) == 2:
+		switch args[1] {
+		case ResetAlways, ResetWhenEmpty, ResetNever:
+			EditZoneResetPolicy(p, z, args[1])
+			p.WriteString("Reset policy set to " + args[1] + ".\n")
+		default:
+			p.WriteString(zoneUsage)
+		}
+	case args[0] == "owner" && len(args) == 3 && args[1] == "add":
+		EditZoneOwner(p, z, args[2], true)
+		p.WriteString(args[2] + " now owns " + z.name + ".\n")
+	case args[0] == "owner" && len(args) == 3 && args[1] == "remove":
+		EditZoneOwner(p, z, args[2], false)
+		p.WriteString(args[2] + " no longer owns " + z.name + ".\n")
+	case args[0] == "prop" && len(args) > 1:
+		EditZoneProperty(p, z, args[1], strings.Join(args[2:], " "))
+		p.WriteString("Zone property " + args[1] + " set.\n")
+	default:
+		p.WriteString(zoneUsage)
+	}
+}
+
+func zoneEcho(p *Player, args []string) {
+	z := p.room.zone
+	if z == nil || !z.CanEdit(p) {
+		p.WriteString("You can only announce in zones you own.\n")
+		return
+	}
+	if len(args) == 0 {
+		p.WriteString("Zecho usage: zecho [announcement].\n")
+		return
+	}
+	z.Broadcast(ZoneAnnounce(z, strings.Join(args, " ")))
+}
@@ -0,0 +1,83 @@
+package mud
+
+import "testing"
+
+func TestZonePropertyInheritance(t *testing.T) {
+	u := testUniverse()
+	z := NewZone(u, "Old Town")
+	z.SetProperty("outdoors", "yes")
+	z.SetProperty("light", "dim")
+	r := quietRoom(u)
+	r.SetZone(z)
+	r.SetProperty("light", "bright")
+
+	if value, ok := r.Property("outdoors"); !ok || value != "yes" {
+		t.Errorf("room should inherit outdoors from its zone, got %q", value)
+	}
+	if value, _ := r.Property("light"); value != "bright" {
+		t.Errorf("room property should override the zone's, got %q", value)
+	}
+	r.SetProperty("light", "")
+	if value, _ := r.Property("light"); value != "dim" {
+		t.Errorf("removing the room's light should expose the zone's, got %q", value)
+	}
+	r.SetZone(nil)
+	if _, ok := r.Property("outdoors"); ok {
+		t.Error("a room outside any zone shouldn't have zone properties")
+	}
+
+	z.Save()
+	loaded := LoadZone(u, z.ID())
+	if value, _ := loaded.Property("outdoors"); value != "yes" || loaded.Name() != "Old Town" {
+		t.Errorf("zone reloaded as %q with outdoors %q", loaded.Name(), value)
+	}
+}
+
+func TestZoneCanEdit(t *testing.T) {
+	u := testUniverse()
+	z := NewZone(u, "Old Town")
+	r := quietRoom(u)
+	r.SetZone(z)
+	alice := &Player{name: "Alice", builder: true}
+	bob := &Player{name: "Bob", builder: true}
+	carol := &Player{name: "Carol"}
+
+	if !z.CanEdit(alice) || !z.CanEdit(bob) || !r.CanEdit(bob) {
+		t.Error("any builder should edit a zone without owners")
+	}
+	if z.CanEdit(carol) || r.CanEdit(carol) {
+		t.Error("non-builders should never edit")
+	}
+	z.AddOwner("Alice")
+	if !z.CanEdit(alice) || !r.CanEdit(alice) {
+		t.Error("owners should edit their zone and its rooms")
+	}
+	if z.CanEdit(bob) || r.CanEdit(bob) {
+		t.Error("builders shouldn't edit zones owned by others")
+	}
+	r.SetZone(nil)
+	if !r.CanEdit(bob) {
+		t.Error("builders should edit rooms outside any zone")
+	}
+}
+
+func TestOnlyAdminsPromote(t *testing.T) {
+	u := testUniverse()
+	r := quietRoom(u)
+	p, socket := testPlayer(r)
+	p.builder = true
+	bob, _ := testPlayer(r)
+	bob.name = "Bob"
+	u.Players[bob.id] = bob
+
+	promote(p, []string{"bob"})
+	if bob.builder || socket.written != "Only admins may promote players.\n" {
+		t.Errorf("a builder promoted Bob: %q", socket.written)
+	}
+	AdminNames["Alice"] = true
+	defer delete(AdminNames, "Alice")
+	promote(p, []string{"bob"})
+	if !bob.builder {
+		t.Error("an admin should be able to promote Bob")
+	}
+}
@@ -6,8 +6,11 @@ import ("fmt"
 
 func init() {
 	mud.Loaders["fruitTree"] = LoadFruitTree
-	mud.PersistentKeys["fruitTree"] = []string { "id", "fruitName", "nextFlowering" }
+	mud.PersistentKeys["fruitTree"] = []string { "id", "fruitName", "flowerIn" }
 	mud.PlayerPerceptions["flower"] = DoesPerceiveFlower
+	mud.Prototypes["peach tree"] = func(u *mud.Universe) mud.PhysicalObject {
+		return MakeFruitTree(u, "peach")
+	}
 }
 
 type FruitTree struct {
@@ -17,7 +20,7 @@ type FruitTree struct {
 	universe *mud.Universe
 	room *mud.Room
 	fruitName string
-	nextFlowering int
+	flowerIn int
 	id int
 	ping chan int
 }
@@ -50,20 +53,32 @@ func (f *FruitTree) Bloom() {
 	mud.Log("Bloom in room",f.room)
 	newFruit := MakeFruit(f.universe, f.fruitName)
 	f.room.Broadcast(TreeFlowerStimulus{ft: f})
-	f.room.AddChild(newFruit)
+	if !mud.MergeStack(f.universe, f.room.PhysicalObjects(), newFruit) {
+		f.room.AddChild(newFruit)
+	}
 }
 
+/*
+ UpdateTimeLoop counts down to the next flowering, faster or slower
+ with the weather. flowerIn is the number of heartbeats of
+ growth left, or -1 if no flowering is due yet.
+ */
 func (f *FruitTree) UpdateTimeLoop() {
 	base := 300000
 	margin := 1250
+	grown := 0.0
 
 	for {
-		now := <- f.ping
-		if f.nextFlowering == -1 {
-			f.nextFlowering = now + randRange(base,margin)
+		<- f.ping
+		if f.flowerIn < 0 {
+			f.flowerIn = randRange(base,margin)
 		}
-		if now == f.nextFlowering {
-			f.nextFlowering = now + randRange(base,margin)
+		grown += mud.GrowthFactor(f.room)
+		for ; grown >= 1 && f.flowerIn > 0; grown-- {
+			f.flowerIn--
+		}
+		if f.flowerIn == 0 {
+			f.flowerIn = randRange(base,margin)
 			f.Bloom()
 		}
 	}
@@ -75,7 +90,7 @@ func (f *FruitTree) PersistentValues() map[string]interface{} {
 		vals["id"] = strconv.Itoa(f.id)
 	}
 	vals["fruitName"] = f.fruitName
-	vals["nextFlowering"] = strconv.Itoa(f.nextFlowering)
+	vals["flowerIn"] = strconv.Itoa(f.flowerIn)
 	return vals
 }
 
@@ -107,7 +122,7 @@ func MakeFruitTree(u *mud.Universe, fruitName string) *FruitTree {
 	ft := new(FruitTree)
 	ft.universe = u
 	ft.fruitName = fruitName
-	ft.nextFlowering = -1
+	ft.flowerIn = -1
 	ft.ping = make(chan int)
 
 	u.Add(ft)
@@ -123,7 +138,10 @@ func LoadFruitTree(u *mud.Universe, id int) interface{} {
 		mud.FieldJoin(":","fruitTree",strconv.Itoa(id)))
 	ft.id = id
 	ft.fruitName = vals["fruitName"].(string)
-	nextFloweringS := vals["nextFlowering"].(string)
-	ft.nextFlowering, _ = strconv.Atoi(nextFloweringS)
+	// Older saves kept an absolute heartbeat under nextFlowering
+	// rather than a countdown, so those trees start a new one.
+	if flowerIn, ok := vals["flowerIn"].(string); ok {
+		ft.flowerIn, _ = strconv.Atoi(flowerIn)
+	}
 	return ft
 }
\ No newline at end of file